@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+)
+
+// apiKeyLifetime is the expiration given to API key sessions.
+// API keys do not expire, but the SDK requires tokens to.
+const apiKeyLifetime = time.Hour
+
+// APIKeys returns a verifier that accepts static API keys as bearer tokens.
+// keys maps each key to the subject it authenticates.
+func APIKeys(keys map[string]string) mcpauth.TokenVerifier {
+	return func(_ context.Context, token string, _ *http.Request) (*mcpauth.TokenInfo, error) {
+		// Compare against every key, so timing does not leak which one matched
+		var subject string
+		for key, s := range keys {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1 {
+				subject = s
+			}
+		}
+		if subject == "" {
+			return nil, fmt.Errorf("%w: unknown API key", mcpauth.ErrInvalidToken)
+		}
+
+		info := (&Principal{Subject: subject}).tokenInfo()
+		info.Expiration = time.Now().Add(apiKeyLifetime)
+		return info, nil
+	}
+}
+
+// ParseAPIKeys parses "subject:key" entries separated by newlines or commas.
+// Blank lines and lines starting with # are ignored.
+func ParseAPIKeys(text string) (map[string]string, error) {
+	keys := make(map[string]string)
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		for entry := range strings.SplitSeq(line, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+
+			subject, key, ok := strings.Cut(entry, ":")
+			subject, key = strings.TrimSpace(subject), strings.TrimSpace(key)
+			if !ok || subject == "" || key == "" {
+				return nil, fmt.Errorf("invalid API key entry, want subject:key")
+			}
+			if _, ok := keys[key]; ok {
+				return nil, fmt.Errorf("duplicate API key for %q", subject)
+			}
+			keys[key] = subject
+		}
+	}
+	return keys, scanner.Err()
+}
+
+// LoadAPIKeys reads API keys from a file in the ParseAPIKeys format.
+func LoadAPIKeys(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseAPIKeys(string(data))
+}
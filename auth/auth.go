@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/oauthex"
+)
+
+// metadataPath is where protected resource metadata is served, per RFC 9728.
+const metadataPath = "/.well-known/oauth-protected-resource"
+
+// principalKey stores the Principal in TokenInfo.Extra.
+const principalKey = "chimera.principal"
+
+// Principal is an authenticated caller.
+type Principal struct {
+	Subject string
+	Scopes  []string
+	// Groups are read from the JWT groups claim, and are empty for API keys.
+	Groups []string
+	// Claims holds every JWT claim, and is empty for API keys.
+	Claims map[string]any
+}
+
+// tokenInfo wraps a principal for the SDK, which carries it into
+// the context of HTTP requests and MCP handlers alike.
+func (p *Principal) tokenInfo() *mcpauth.TokenInfo {
+	return &mcpauth.TokenInfo{
+		Scopes: p.Scopes,
+		Extra:  map[string]any{principalKey: p},
+	}
+}
+
+// FromContext returns the principal that authenticated the request, or nil.
+func FromContext(ctx context.Context) *Principal {
+	return PrincipalOf(mcpauth.TokenInfoFromContext(ctx))
+}
+
+// PrincipalOf returns the principal behind token info, such as an
+// MCP request's Extra.TokenInfo, or nil.
+func PrincipalOf(info *mcpauth.TokenInfo) *Principal {
+	if info == nil {
+		return nil
+	}
+	p, _ := info.Extra[principalKey].(*Principal)
+	return p
+}
+
+// Chain returns a verifier that accepts tokens any of verifiers accept.
+func Chain(verifiers ...mcpauth.TokenVerifier) mcpauth.TokenVerifier {
+	return func(ctx context.Context, token string, req *http.Request) (*mcpauth.TokenInfo, error) {
+		err := mcpauth.ErrInvalidToken
+		for _, verify := range verifiers {
+			var info *mcpauth.TokenInfo
+			info, err = verify(ctx, token, req)
+			if !errors.Is(err, mcpauth.ErrInvalidToken) {
+				return info, err
+			}
+		}
+		return nil, err
+	}
+}
+
+// Options configures Handler.
+type Options struct {
+	// Verifier authenticates bearer tokens. Use Chain to accept several kinds.
+	Verifier mcpauth.TokenVerifier
+	// Scopes are required of every caller.
+	Scopes []string
+	// Metadata, if set, is served at /.well-known/oauth-protected-resource
+	// and advertised to unauthenticated callers. Its Resource must be the
+	// absolute URL of the endpoint.
+	Metadata *oauthex.ProtectedResourceMetadata
+}
+
+// Handler requires callers of next to present a bearer token the verifier accepts.
+// The principal is available to next through FromContext.
+func Handler(next http.Handler, opts Options) (http.Handler, error) {
+	if opts.Verifier == nil {
+		return nil, errors.New("auth requires a verifier")
+	}
+
+	bearer := &mcpauth.RequireBearerTokenOptions{Scopes: opts.Scopes}
+	mux := http.NewServeMux()
+
+	if opts.Metadata != nil {
+		metadataURL, err := resourceMetadataURL(opts.Metadata.Resource)
+		if err != nil {
+			return nil, err
+		}
+		bearer.ResourceMetadataURL = metadataURL
+
+		data, err := json.Marshal(opts.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		serve := func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if _, err := w.Write(data); err != nil {
+				slog.Error("failed to write resource metadata", "err", err)
+			}
+		}
+		mux.HandleFunc("GET "+metadataPath, serve)
+		mux.HandleFunc("GET "+metadataPath+"/", serve)
+	}
+
+	mux.Handle("/", mcpauth.RequireBearerToken(opts.Verifier, bearer)(next))
+	return mux, nil
+}
+
+// resourceMetadataURL returns where clients find the metadata for resource,
+// by inserting the well-known path between its host and path.
+func resourceMetadataURL(resource string) (string, error) {
+	u, err := url.Parse(resource)
+	if err != nil {
+		return "", err
+	}
+	if !u.IsAbs() {
+		return "", errors.New("protected resource must be an absolute URL")
+	}
+
+	u.Path = metadataPath + strings.TrimSuffix(u.Path, "/")
+	u.RawPath, u.RawQuery, u.Fragment = "", "", ""
+	return u.String(), nil
+}
@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/oauthex"
+)
+
+func TestHandler(t *testing.T) {
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, FromContext(r.Context()).Subject)
+	})
+
+	handler, err := Handler(echo, Options{
+		Verifier: APIKeys(map[string]string{"secret": "alice"}),
+		Metadata: &oauthex.ProtectedResourceMetadata{
+			Resource:             "https://chimera.example/mcp",
+			AuthorizationServers: []string{"https://auth.example"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	tests := []struct {
+		token string
+		code  int
+		body  string
+	}{
+		{"secret", http.StatusOK, "alice"},
+		{"wrong", http.StatusUnauthorized, ""},
+		{"", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/mcp", nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body := readAll(t, resp)
+
+		if resp.StatusCode != tt.code {
+			t.Errorf("token %q: expected status %d, got %d", tt.token, tt.code, resp.StatusCode)
+		}
+		if tt.code == http.StatusOK && body != tt.body {
+			t.Errorf("token %q: expected principal %q, got %q", tt.token, tt.body, body)
+		}
+		if tt.code == http.StatusUnauthorized {
+			want := "Bearer resource_metadata=https://chimera.example/.well-known/oauth-protected-resource/mcp"
+			if got := resp.Header.Get("WWW-Authenticate"); got != want {
+				t.Errorf("Expected challenge %q, got %q", want, got)
+			}
+		}
+	}
+
+	// Metadata needs no token
+	resp, err := http.Get(server.URL + "/.well-known/oauth-protected-resource/mcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var metadata oauthex.ProtectedResourceMetadata
+	if err := json.Unmarshal([]byte(readAll(t, resp)), &metadata); err != nil {
+		t.Fatalf("Failed to decode metadata: %v", err)
+	}
+	if metadata.Resource != "https://chimera.example/mcp" {
+		t.Errorf("Expected resource metadata, got %+v", metadata)
+	}
+}
+
+func readAll(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	keys, err := ParseAPIKeys("# team keys\nalice:one, bob:two\n\ncarol: three\n")
+	if err != nil {
+		t.Fatalf("Failed to parse keys: %v", err)
+	}
+
+	want := map[string]string{"one": "alice", "two": "bob", "three": "carol"}
+	if fmt.Sprint(keys) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, keys)
+	}
+
+	for _, bad := range []string{"alice", ":key", "alice:one,bob:one"} {
+		if _, err := ParseAPIKeys(bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+func TestChain(t *testing.T) {
+	verify := Chain(
+		APIKeys(map[string]string{"one": "alice"}),
+		APIKeys(map[string]string{"two": "bob"}),
+	)
+
+	info, err := verify(t.Context(), "two", nil)
+	if err != nil {
+		t.Fatalf("Expected second verifier to accept token: %v", err)
+	}
+	if p := PrincipalOf(info); p == nil || p.Subject != "bob" {
+		t.Errorf("Expected bob, got %+v", p)
+	}
+
+	if _, err := verify(t.Context(), "three", nil); err == nil {
+		t.Error("Expected unknown token to be rejected")
+	}
+}
@@ -0,0 +1,3 @@
+// Package auth authenticates callers of the chimera HTTP endpoint,
+// with static API keys or JWTs, and serves OAuth protected resource metadata.
+package auth
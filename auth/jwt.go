@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+)
+
+// leeway tolerates clock skew when checking exp and nbf.
+const leeway = time.Minute
+
+// algorithms are the JWS algorithms tokens may be signed with.
+// Each key only verifies the algorithms of its own type.
+var algorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// JWKS is a set of public keys, keyed by key ID.
+type JWKS struct {
+	keys jose.JSONWebKeySet
+}
+
+// ParseJWKS parses a JSON Web Key Set. RSA, EC and Ed25519 keys are supported.
+func ParseJWKS(data []byte) (*JWKS, error) {
+	var set jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	jwks := &JWKS{}
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		if !key.IsPublic() {
+			return nil, fmt.Errorf("key %q is not a public key", key.KeyID)
+		}
+		jwks.keys.Keys = append(jwks.keys.Keys, key)
+	}
+
+	if len(jwks.keys.Keys) == 0 {
+		return nil, errors.New("no signing keys in key set")
+	}
+	return jwks, nil
+}
+
+// LoadJWKS reads a JSON Web Key Set from a file.
+func LoadJWKS(path string) (*JWKS, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+// key returns the key a token header names,
+// or the only key if the header names none.
+func (s *JWKS) key(kid string) (any, bool) {
+	if kid == "" && len(s.keys.Keys) == 1 {
+		return s.keys.Keys[0], true
+	}
+	keys := s.keys.Key(kid)
+	if len(keys) == 0 {
+		return nil, false
+	}
+	return keys[0], true
+}
+
+// JWT returns a verifier that accepts JWTs signed by a key in jwks.
+// issuer and audience, when set, must match the token's iss and aud claims.
+// Scopes are read from the scope or scp claim, and groups from the groups claim.
+func JWT(jwks *JWKS, issuer, audience string) mcpauth.TokenVerifier {
+	return func(_ context.Context, token string, _ *http.Request) (*mcpauth.TokenInfo, error) {
+		info, err := jwks.verify(token, issuer, audience, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", mcpauth.ErrInvalidToken, err)
+		}
+		return info, nil
+	}
+}
+
+// verify checks a token's signature and registered claims,
+// and builds its token info.
+func (s *JWKS) verify(token, issuer, audience string, now time.Time) (*mcpauth.TokenInfo, error) {
+	parsed, err := jwt.ParseSigned(token, algorithms)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := s.key(parsed.Headers[0].KeyID)
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", parsed.Headers[0].KeyID)
+	}
+
+	var registered jwt.Claims
+	var claims jwtClaims
+	if err := parsed.Claims(key, &registered, &claims); err != nil {
+		return nil, err
+	}
+
+	if registered.Expiry == nil {
+		return nil, errors.New("missing exp claim")
+	}
+	expected := jwt.Expected{Issuer: issuer, Time: now}
+	if audience != "" {
+		expected.AnyAudience = jwt.Audience{audience}
+	}
+	if err := registered.ValidateWithLeeway(expected, leeway); err != nil {
+		return nil, err
+	}
+
+	scopes := claims.strings("scp")
+	if scope, ok := claims["scope"].(string); ok {
+		scopes = strings.Fields(scope)
+	}
+
+	p := &Principal{Subject: registered.Subject, Scopes: scopes, Groups: claims.strings("groups"), Claims: claims}
+	info := p.tokenInfo()
+	info.Expiration = registered.Expiry.Time()
+	return info, nil
+}
+
+// jwtClaims are a token's decoded claims.
+type jwtClaims map[string]any
+
+// strings reads a claim that may be a string or an array of strings.
+func (c jwtClaims) strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var out []string
+		for _, s := range v {
+			if s, ok := s.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
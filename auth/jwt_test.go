@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// signer signs test tokens and publishes its key.
+type signer struct {
+	alg string
+	kid string
+	jwk map[string]string
+	// sign signs a SHA-256 digest, or the message itself for EdDSA
+	sign func(message []byte) []byte
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func newSigners(t *testing.T) []signer {
+	t.Helper()
+
+	ec, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ecBytes, _ := ec.PublicKey.Bytes()
+	return []signer{
+		{
+			alg: "ES256", kid: "ec",
+			jwk: map[string]string{"kty": "EC", "crv": "P-256", "x": b64(ecBytes[1:33]), "y": b64(ecBytes[33:])},
+			sign: func(message []byte) []byte {
+				digest := sha256.Sum256(message)
+				r, s, err := ecdsa.Sign(rand.Reader, ec, digest[:])
+				if err != nil {
+					t.Fatal(err)
+				}
+				return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+			},
+		},
+		{
+			alg: "RS256", kid: "rsa",
+			jwk: map[string]string{"kty": "RSA", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+			sign: func(message []byte) []byte {
+				digest := sha256.Sum256(message)
+				sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+				if err != nil {
+					t.Fatal(err)
+				}
+				return sig
+			},
+		},
+		{
+			alg: "EdDSA", kid: "ed",
+			jwk: map[string]string{"kty": "OKP", "crv": "Ed25519", "x": b64(edPub)},
+			sign: func(message []byte) []byte {
+				return ed25519.Sign(edKey, message)
+			},
+		},
+	}
+}
+
+// token signs claims with s, under the given algorithm header.
+func (s signer) token(t *testing.T, alg string, claims map[string]any) string {
+	t.Helper()
+
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": s.kid, "typ": "JWT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed := b64(header) + "." + b64(payload)
+	return signed + "." + b64(s.sign([]byte(signed)))
+}
+
+func keySet(t *testing.T, signers []signer) *JWKS {
+	t.Helper()
+
+	var keys []map[string]string
+	for _, s := range signers {
+		jwk := map[string]string{"kid": s.kid, "use": "sig"}
+		for k, v := range s.jwk {
+			jwk[k] = v
+		}
+		keys = append(keys, jwk)
+	}
+
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, err := ParseJWKS(data)
+	if err != nil {
+		t.Fatalf("Failed to parse key set: %v", err)
+	}
+	return jwks
+}
+
+func TestJWT(t *testing.T) {
+	signers := newSigners(t)
+	verify := JWT(keySet(t, signers), "https://auth.example", "chimera")
+
+	now := time.Now().Unix()
+	valid := map[string]any{
+		"iss":    "https://auth.example",
+		"aud":    []string{"other", "chimera"},
+		"sub":    "alice",
+		"scope":  "tools:read tools:call",
+		"groups": []string{"ops"},
+		"exp":    now + 60,
+	}
+	with := func(key string, value any) map[string]any {
+		claims := map[string]any{}
+		for k, v := range valid {
+			claims[k] = v
+		}
+		claims[key] = value
+		return claims
+	}
+
+	for _, s := range signers {
+		info, err := verify(t.Context(), s.token(t, s.alg, valid), nil)
+		if err != nil {
+			t.Fatalf("%s: expected valid token: %v", s.alg, err)
+		}
+		p := PrincipalOf(info)
+		if p.Subject != "alice" || len(p.Scopes) != 2 || len(p.Groups) != 1 || p.Claims["iss"] != "https://auth.example" {
+			t.Errorf("%s: unexpected principal %+v", s.alg, p)
+		}
+	}
+
+	// Clock skew is tolerated on either side
+	s := signers[0]
+	for _, claims := range []map[string]any{with("exp", now-30), with("nbf", now+30)} {
+		if _, err := verify(t.Context(), s.token(t, s.alg, claims), nil); err != nil {
+			t.Errorf("expected token within leeway to be valid: %v", err)
+		}
+	}
+
+	tests := map[string]string{
+		"expired":        s.token(t, s.alg, with("exp", now-3600)),
+		"no expiry":      s.token(t, s.alg, with("exp", nil)),
+		"not yet valid":  s.token(t, s.alg, with("nbf", now+3600)),
+		"wrong issuer":   s.token(t, s.alg, with("iss", "https://evil.example")),
+		"wrong audience": s.token(t, s.alg, with("aud", "other")),
+		"wrong alg":      s.token(t, "RS256", valid),
+		"none alg":       s.token(t, "none", valid),
+		"tampered":       s.token(t, s.alg, valid)[:20] + "x" + s.token(t, s.alg, valid)[21:],
+		"malformed":      "not.a.jwt.at.all",
+	}
+	for name, token := range tests {
+		if _, err := verify(t.Context(), token, nil); err == nil {
+			t.Errorf("%s: expected token to be rejected", name)
+		}
+	}
+}
@@ -12,6 +12,7 @@ type Client struct {
 	command string
 	args    []string
 	env     []string
+	dir     string
 }
 
 // NewClient creates a stdio client that spawns the given command.
@@ -24,9 +25,16 @@ func NewClient(command string, args []string, env []string) *Client {
 	}
 }
 
+// WithDir sets the working directory of the command, and returns c.
+func (c *Client) WithDir(dir string) *Client {
+	c.dir = dir
+	return c
+}
+
 // Transport provides a new transport for each session.
 func (c *Client) Transport(ctx context.Context) mcp.Transport {
 	cmd := exec.CommandContext(ctx, c.command, c.args...)
 	cmd.Env = c.env
+	cmd.Dir = c.dir
 	return &mcp.CommandTransport{Command: cmd}
 }
@@ -2,6 +2,7 @@ package stream
 
 import (
 	"context"
+	"log/slog"
 	"net/http"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -9,13 +10,49 @@ import (
 
 // Client manages an HTTP-based MCP server connection.
 type Client struct {
-	url        string
-	httpClient *http.Client
+	url string
+	// sse selects the legacy HTTP+SSE transport
+	sse bool
+	// headers are templates, resolved for each session
+	headers map[string]string
 }
 
 // NewClient creates an HTTP client with the given URL and headers.
 // Headers are added to all requests (useful for authentication).
+// Header values may reference secrets and the calling session,
+// which are resolved each time a session connects; see Expand.
 func NewClient(url string, headers map[string]string) *Client {
+	return &Client{
+		url:     url,
+		headers: headers,
+	}
+}
+
+// NewSSEClient creates a client for servers that only speak the
+// legacy HTTP+SSE transport.
+func NewSSEClient(url string, headers map[string]string) *Client {
+	c := NewClient(url, headers)
+	c.sse = true
+	return c
+}
+
+// Transport provides a new transport for each session.
+// It returns nil if a header cannot be resolved.
+func (c *Client) Transport(ctx context.Context) mcp.Transport {
+	headers := make(map[string]string, len(c.headers))
+	for key, template := range c.headers {
+		value, err := Expand(ctx, template)
+		if err != nil {
+			slog.Error("failed to resolve header", "url", c.url, "header", key, "err", err)
+			return nil
+		}
+
+		// Omit headers that resolve to nothing, such as absent caller headers
+		if value != "" {
+			headers[key] = value
+		}
+	}
+
 	httpClient := &http.Client{
 		Transport: &CustomTransport{
 			Transport: http.DefaultTransport,
@@ -23,17 +60,15 @@ func NewClient(url string, headers map[string]string) *Client {
 		},
 	}
 
-	return &Client{
-		url:        url,
-		httpClient: httpClient,
+	if c.sse {
+		return &mcp.SSEClientTransport{
+			Endpoint:   c.url,
+			HTTPClient: httpClient,
+		}
 	}
-}
-
-// Transport provides a new transport for each session.
-func (c *Client) Transport(_ context.Context) mcp.Transport {
 	return &mcp.StreamableClientTransport{
 		Endpoint:   c.url,
-		HTTPClient: c.httpClient,
+		HTTPClient: httpClient,
 	}
 }
 
@@ -0,0 +1,78 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/njayp/chimera/auth"
+	"github.com/njayp/chimera/proxy"
+)
+
+// reference matches ${...} in header templates.
+var reference = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// Expand resolves the references in a header template:
+//
+//   - ${env:NAME} is an environment variable
+//   - ${file:/path} is a file's contents, without trailing newlines
+//   - ${header:Name} is a header of the request that opened the calling session,
+//     or empty if it had none
+//   - ${principal.subject} and ${principal.<claim>} describe the authenticated caller
+//
+// Caller references are resolved from ctx, so they are only available to
+// backends that are not shared.
+func Expand(ctx context.Context, template string) (string, error) {
+	var errs []error
+	value := reference.ReplaceAllStringFunc(template, func(match string) string {
+		resolved, err := resolve(ctx, match[2:len(match)-1])
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return resolved
+	})
+	return value, errors.Join(errs...)
+}
+
+func resolve(ctx context.Context, ref string) (string, error) {
+	if name, ok := strings.CutPrefix(ref, "env:"); ok {
+		value, exists := os.LookupEnv(name)
+		if !exists {
+			return "", fmt.Errorf("environment variable %q is not set", name)
+		}
+		return value, nil
+	}
+
+	if path, ok := strings.CutPrefix(ref, "file:"); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	if name, ok := strings.CutPrefix(ref, "header:"); ok {
+		return proxy.RequestHeader(ctx).Get(name), nil
+	}
+
+	if claim, ok := strings.CutPrefix(ref, "principal."); ok {
+		p := auth.FromContext(ctx)
+		if p == nil {
+			return "", fmt.Errorf("%q needs an authenticated caller", ref)
+		}
+		if claim == "subject" {
+			return p.Subject, nil
+		}
+
+		value, exists := p.Claims[claim]
+		if !exists {
+			return "", fmt.Errorf("caller has no %q claim", claim)
+		}
+		return fmt.Sprint(value), nil
+	}
+
+	return "", fmt.Errorf("unknown reference %q", ref)
+}
@@ -0,0 +1,124 @@
+package stream
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/njayp/chimera/auth"
+	"github.com/njayp/chimera/proxy"
+)
+
+func TestExpand(t *testing.T) {
+	t.Setenv("STREAM_TEST_TOKEN", "env-secret")
+
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("file-secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		template string
+		want     string
+		err      bool
+	}{
+		{"static", "static", false},
+		{"Bearer ${env:STREAM_TEST_TOKEN}", "Bearer env-secret", false},
+		{"Bearer ${file:" + path + "}", "Bearer file-secret", false},
+		{"${header:Authorization}", "", false},
+		{"${env:STREAM_TEST_MISSING}", "", true},
+		{"${principal.subject}", "", true},
+		{"${unknown}", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := Expand(context.Background(), tt.template)
+		if (err != nil) != tt.err {
+			t.Errorf("Expand(%q) error = %v, want error %v", tt.template, err, tt.err)
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("Expand(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+type provider struct {
+	clients proxy.Clients
+}
+
+func (p *provider) Clients() proxy.Clients {
+	return p.clients
+}
+
+func TestCallerHeaders(t *testing.T) {
+	// Backend records the headers it sees
+	var mu sync.Mutex
+	seen := http.Header{}
+	backend := mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server {
+		mu.Lock()
+		defer mu.Unlock()
+		seen = r.Header.Clone()
+		return mcp.NewServer(&mcp.Implementation{Name: "backend"}, nil)
+	}, nil)
+	backendServer := httptest.NewServer(backend)
+	defer backendServer.Close()
+
+	client := NewClient(backendServer.URL, map[string]string{
+		"Authorization": "${header:Authorization}",
+		"X-User":        "${principal.subject}",
+		"X-Absent":      "${header:X-Absent}",
+	})
+	handler, err := auth.Handler(
+		proxy.Handler(&provider{clients: proxy.Clients{"backend": client}}),
+		auth.Options{Verifier: auth.APIKeys(map[string]string{"key": "alice"})},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxyServer := httptest.NewServer(handler)
+	defer proxyServer.Close()
+
+	frontend := NewClient(proxyServer.URL, map[string]string{"Authorization": "Bearer key"})
+	c := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil)
+	session, err := c.Connect(t.Context(), frontend.Transport(t.Context()), nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer closeWithin(t, session, 5*time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got := seen.Get("Authorization"); got != "Bearer key" {
+		t.Errorf("Expected forwarded Authorization, got %q", got)
+	}
+	if got := seen.Get("X-User"); got != "alice" {
+		t.Errorf("Expected X-User alice, got %q", got)
+	}
+	if _, ok := seen["X-Absent"]; ok {
+		t.Error("Expected absent header to be omitted")
+	}
+}
+
+// closeWithin closes session, failing the test instead of hanging
+// if teardown takes longer than timeout.
+func closeWithin(t *testing.T, session *mcp.ClientSession, timeout time.Duration) {
+	t.Helper()
+
+	closed := make(chan struct{})
+	go func() {
+		_ = session.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(timeout):
+		t.Errorf("Session did not close within %v", timeout)
+	}
+}
@@ -0,0 +1,2 @@
+// Package config holds what the configuration formats share.
+package config
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/njayp/chimera/proxy"
+)
+
+// FieldError reports a problem with one field, by its path in the file.
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Field returns a FieldError for the field at path.
+func Field(path, format string, args ...any) error {
+	return &FieldError{Path: path, Err: fmt.Errorf(format, args...)}
+}
+
+// ValidateURL checks that the field at path holds an absolute http(s) URL.
+func ValidateURL(path, raw string) error {
+	if raw == "" {
+		return Field(path, "missing URL")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return &FieldError{Path: path, Err: err}
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Field(path, "%q is not an http or https URL", raw)
+	}
+	return nil
+}
+
+// ValidateOptions checks the proxy options of each server in section,
+// and that no two servers expose names under the same prefix.
+func ValidateOptions(section string, servers map[string]proxy.Options) error {
+	var errs []error
+	owners := make(map[string]string)
+	for _, name := range slices.Sorted(maps.Keys(servers)) {
+		path := section + "." + name
+		options := servers[name]
+		errs = append(errs, validateOptions(path, options))
+
+		prefix := name
+		if options.Alias != "" {
+			prefix = options.Alias
+		}
+		if owner, ok := owners[prefix]; ok {
+			errs = append(errs, Field(path, "prefix %q is already used by %s", prefix, owner))
+			continue
+		}
+		owners[prefix] = name
+
+		if options.Alias == "" && strings.ContainsAny(name, "/?#") {
+			errs = append(errs, Field(path, "name must not contain /, ? or #, or set an alias"))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateOptions checks the proxy options of the server at path.
+func validateOptions(path string, options proxy.Options) error {
+	var errs []error
+	if options.LogLevel != "" && !proxy.ValidLevel(options.LogLevel) {
+		errs = append(errs, Field(path+".logLevel", "unknown level %q", options.LogLevel))
+	}
+	if options.Sampling.PerMinute < 0 {
+		errs = append(errs, Field(path+".sampling.perMinute", "must not be negative"))
+	}
+	if options.Elicitation.PerMinute < 0 {
+		errs = append(errs, Field(path+".elicitation.perMinute", "must not be negative"))
+	}
+	// The prefix is the authority of rewritten resource URIs
+	if strings.ContainsAny(options.Alias, "/?#") {
+		errs = append(errs, Field(path+".alias", "%q must not contain /, ? or #", options.Alias))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,154 @@
+// Package kube reads configs from Kubernetes ConfigMaps through the API,
+// which notices changes sooner than a mounted volume and needs no mount.
+package kube
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"os"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// namespaceFile holds the pod's namespace when running in a cluster.
+const namespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// retry is the delay before re-establishing a failed watch,
+// a variable so tests can shorten it.
+var retry = 5 * time.Second
+
+// Source reads a config from a ConfigMap, and watches it for changes.
+// Each data key is a file, as when the ConfigMap is mounted, so one
+// ConfigMap can hold several files to merge. It implements watcher.Source.
+type Source struct {
+	Client    kubernetes.Interface
+	Namespace string
+	Name      string
+}
+
+// NewSource creates a source for the ConfigMap namespace/name.
+func NewSource(client kubernetes.Interface, namespace, name string) *Source {
+	return &Source{
+		Client:    client,
+		Namespace: namespace,
+		Name:      name,
+	}
+}
+
+// InCluster creates a source using the pod's service account.
+// An empty namespace means the pod's own.
+func InCluster(namespace, name string) (*Source, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if namespace == "" {
+		data, err := os.ReadFile(namespaceFile)
+		if err != nil {
+			return nil, err
+		}
+		namespace = strings.TrimSpace(string(data))
+	}
+	return NewSource(client, namespace, name), nil
+}
+
+// Read returns the ConfigMap's data and binary data, by key.
+func (s *Source) Read(ctx context.Context) (map[string][]byte, error) {
+	cm, err := s.Client.CoreV1().ConfigMaps(s.Namespace).Get(ctx, s.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	files := maps.Clone(cm.BinaryData)
+	if files == nil {
+		files = make(map[string][]byte, len(cm.Data))
+	}
+	for key, value := range cm.Data {
+		files[key] = []byte(value)
+	}
+	return files, nil
+}
+
+// Watch watches the ConfigMap, calling onChange whenever it is added,
+// modified or deleted. Watches that end are re-established.
+func (s *Source) Watch(ctx context.Context, onChange func()) error {
+	// Start the first watch now, so that changes made as soon as
+	// Watch returns are not missed
+	w, err := s.watch(ctx)
+	if err != nil {
+		return err
+	}
+
+	delay := retry
+	go func() {
+		for {
+			s.relay(ctx, w, onChange)
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(delay):
+				}
+
+				if w, err = s.watch(ctx); err == nil {
+					break
+				}
+				slog.Error("failed to watch configmap", "namespace", s.Namespace, "name", s.Name, "error", err)
+			}
+
+			// Changes may have been missed while the watch was down
+			onChange()
+		}
+	}()
+	return nil
+}
+
+func (s *Source) watch(ctx context.Context) (watch.Interface, error) {
+	return s.Client.CoreV1().ConfigMaps(s.Namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", s.Name).String(),
+	})
+}
+
+// relay calls onChange for each event until the watch or ctx ends.
+func (s *Source) relay(ctx context.Context, w watch.Interface, onChange func()) {
+	defer w.Stop()
+	for {
+		select {
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return
+			}
+			if event.Type == watch.Error {
+				slog.Error("configmap watch failed", "namespace", s.Namespace, "name", s.Name,
+					"error", apierrors.FromObject(event.Object))
+				return
+			}
+			// Not every server honors the field selector
+			if cm, ok := event.Object.(*corev1.ConfigMap); ok && cm.Name != s.Name {
+				continue
+			}
+			onChange()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Source) String() string {
+	return "configmap://" + s.Namespace + "/" + s.Name
+}
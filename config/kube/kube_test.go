@@ -0,0 +1,110 @@
+package kube
+
+import (
+	"testing"
+	"time"
+
+	"github.com/njayp/chimera/config/watcher"
+	"github.com/njayp/chimera/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func configMap(data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "mcp", Name: "chimera"},
+		Data:       data,
+	}
+}
+
+func TestSource(t *testing.T) {
+	ctx := t.Context()
+	client := fake.NewClientset(configMap(map[string]string{
+		"10-a.json": `{"servers": {"a": {"type": "http", "url": "http://localhost/a"}}}`,
+		"20-b.yaml": "mcpServers:\n  b:\n    command: mcp-b\n",
+	}))
+
+	w, err := watcher.NewFromSource[watcher.AutoConfig](ctx, NewSource(client, "mcp", "chimera"), watcher.Options{})
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+	if clients := w.Clients(); len(clients) != 2 {
+		t.Fatalf("expected the files to be merged into 2 clients, got %v", clients)
+	}
+
+	// Other ConfigMaps are ignored
+	other := configMap(map[string]string{"c.json": `{"servers": {}}`})
+	other.Name = "other"
+	if _, err := client.CoreV1().ConfigMaps("mcp").Create(ctx, other, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	updated := configMap(map[string]string{
+		"mcp.json": `{"servers": {"c": {"type": "http", "url": "http://localhost/c"}}}`,
+	})
+	if _, err := client.CoreV1().ConfigMaps("mcp").Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !testutil.Eventually(func() bool {
+		clients := w.Clients()
+		return len(clients) == 1 && clients["c"] != nil
+	}) {
+		t.Fatalf("expected client c after the update, got %v", w.Clients())
+	}
+
+	// Deleting the ConfigMap keeps the last good config
+	if err := client.CoreV1().ConfigMaps("mcp").Delete(ctx, "chimera", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !testutil.Eventually(func() bool {
+		return len(w.Status().Errors) > 0
+	}) {
+		t.Fatal("expected the deletion to be reported")
+	}
+	if w.Clients()["c"] == nil {
+		t.Error("expected client c to be kept")
+	}
+}
+
+func TestWatchRecovers(t *testing.T) {
+	retry = 10 * time.Millisecond
+	t.Cleanup(func() { retry = 5 * time.Second })
+
+	client := fake.NewClientset()
+	watches := make(chan *watch.FakeWatcher, 10)
+	client.PrependWatchReactor("configmaps", func(k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFake()
+		watches <- w
+		return true, w, nil
+	})
+
+	changes := make(chan struct{}, 10)
+	source := NewSource(client, "mcp", "chimera")
+	if err := source.Watch(t.Context(), func() { changes <- struct{}{} }); err != nil {
+		t.Fatal(err)
+	}
+
+	first := <-watches
+	first.Modify(configMap(nil))
+	<-changes
+
+	// Failed and closed watches are re-established,
+	// reporting a change in case one was missed
+	first.Error(&metav1.Status{Message: "too old resource version"})
+	second := <-watches
+	<-changes
+
+	second.Stop()
+	third := <-watches
+	<-changes
+
+	third.Modify(configMap(nil))
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the new watch to report changes")
+	}
+}
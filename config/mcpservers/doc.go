@@ -0,0 +1,3 @@
+// Package mcpservers loads the mcpServers configuration files used by
+// Claude Desktop, Cursor, Windsurf and similar clients.
+package mcpservers
@@ -0,0 +1,138 @@
+package mcpservers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/njayp/chimera/clients/stdio"
+	"github.com/njayp/chimera/clients/stream"
+	"github.com/njayp/chimera/config"
+	"github.com/njayp/chimera/proxy"
+)
+
+// Config is the structure of an mcpServers configuration file.
+type Config struct {
+	MCPServers map[string]Server `json:"mcpServers"`
+
+	// Naming is the default naming strategy for servers that do not set one.
+	Naming proxy.Naming `json:"naming,omitempty"`
+}
+
+// Server defines a single MCP server.
+// The transport is inferred from command or url when not set.
+type Server struct {
+	// Type and Transport both name the transport, depending on the client:
+	// stdio, http (or streamable-http, streamableHttp) and sse.
+	Type      string            `json:"type,omitempty"`
+	Transport string            `json:"transport,omitempty"`
+	Command   string            `json:"command,omitempty"`
+	Args      []string          `json:"args,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+	Cwd       string            `json:"cwd,omitempty"`
+	URL       string            `json:"url,omitempty"`
+	// ServerURL is Windsurf's name for URL.
+	ServerURL string            `json:"serverUrl,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Disabled  bool              `json:"disabled,omitempty"`
+
+	// chimera-specific settings
+	proxy.Options
+}
+
+// transport resolves the server's transport.
+func (s Server) transport() string {
+	transport := s.Type
+	if transport == "" {
+		transport = s.Transport
+	}
+
+	switch transport {
+	case "":
+		if s.Command != "" {
+			return "stdio"
+		}
+		return "http"
+	case "streamable-http", "streamableHttp":
+		return "http"
+	default:
+		return transport
+	}
+}
+
+// Validate reports every problem with the config, by field path.
+// Disabled servers are checked too, so they still work once enabled.
+func (c Config) Validate() error {
+	var errs []error
+	options := make(map[string]proxy.Options, len(c.MCPServers))
+	for _, name := range slices.Sorted(maps.Keys(c.MCPServers)) {
+		server := c.MCPServers[name]
+		path := "mcpServers." + name
+		options[name] = server.Options
+
+		switch transport := server.transport(); transport {
+		case "stdio":
+			if server.Command == "" {
+				errs = append(errs, config.Field(path+".command", "missing command"))
+			}
+		case "http", "sse":
+			field := ".url"
+			url := server.URL
+			if url == "" && server.ServerURL != "" {
+				field, url = ".serverUrl", server.ServerURL
+			}
+			errs = append(errs, config.ValidateURL(path+field, url))
+		default:
+			errs = append(errs, config.Field(path+".type", "unknown transport %q, want stdio, http or sse", transport))
+		}
+	}
+
+	errs = append(errs, config.ValidateOptions("mcpServers", options))
+	return errors.Join(errs...)
+}
+
+// ToClients converts the mcpServers config format into proxy.Clients.
+// Disabled servers are skipped.
+func (c Config) ToClients() proxy.Clients {
+	clients := make(proxy.Clients)
+	for name, server := range c.MCPServers {
+		if server.Disabled {
+			continue
+		}
+
+		url := server.URL
+		if url == "" {
+			url = server.ServerURL
+		}
+
+		var client proxy.Client
+		switch transport := server.transport(); transport {
+		case "stdio":
+			env := make([]string, 0, len(server.Env))
+			for key, value := range server.Env {
+				env = append(env, fmt.Sprintf("%s=%s", key, value))
+			}
+			// Sort so that reloading an unchanged server yields an equal client
+			slices.Sort(env)
+			client = stdio.NewClient(server.Command, server.Args, env).WithDir(server.Cwd)
+		case "http":
+			client = stream.NewClient(url, server.Headers)
+		case "sse":
+			client = stream.NewSSEClient(url, server.Headers)
+		default:
+			slog.Error("unsupported server type", "name", name, "type", transport)
+			continue
+		}
+
+		options := server.Options
+		if options.Naming == "" {
+			options.Naming = c.Naming
+		}
+
+		clients[name] = proxy.WithOptions(client, options)
+	}
+
+	return clients
+}
@@ -0,0 +1,52 @@
+package mcpservers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestToClients(t *testing.T) {
+	data := `{
+		"mcpServers": {
+			"filesystem": {"command": "npx", "args": ["-y", "server-filesystem"], "cwd": "/tmp"},
+			"remote": {"url": "https://example.com/mcp", "headers": {"Authorization": "Bearer token"}},
+			"legacy": {"serverUrl": "https://example.com/sse", "transport": "sse"},
+			"off": {"command": "npx", "disabled": true},
+			"bad": {"type": "carrier-pigeon"}
+		}
+	}`
+
+	var config Config
+	if err := json.Unmarshal([]byte(data), &config); err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+
+	clients := config.ToClients()
+	for _, name := range []string{"filesystem", "remote", "legacy"} {
+		if _, ok := clients[name]; !ok {
+			t.Errorf("Expected client %q", name)
+		}
+	}
+	if len(clients) != 3 {
+		t.Errorf("Expected 3 clients, got %d", len(clients))
+	}
+}
+
+func TestTransport(t *testing.T) {
+	tests := []struct {
+		server Server
+		want   string
+	}{
+		{Server{Command: "npx"}, "stdio"},
+		{Server{URL: "https://example.com/mcp"}, "http"},
+		{Server{URL: "https://example.com/mcp", Type: "streamable-http"}, "http"},
+		{Server{URL: "https://example.com/mcp", Transport: "streamableHttp"}, "http"},
+		{Server{URL: "https://example.com/sse", Type: "sse"}, "sse"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.server.transport(); got != tt.want {
+			t.Errorf("transport(%+v) = %q, want %q", tt.server, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package vscode
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Input declares a value the user supplies, referenced as ${input:id}.
+// VS Code prompts for inputs; chimera looks them up instead.
+type Input struct {
+	Type        string `json:"type,omitempty"`
+	ID          string `json:"id"`
+	Description string `json:"description,omitempty"`
+	Password    bool   `json:"password,omitempty"`
+	Default     string `json:"default,omitempty"`
+	Options     []any  `json:"options,omitempty"`
+}
+
+// Resolver supplies values for placeholders in server settings.
+//
+// ${input:id} is looked up, in order, in the INPUT_<ID> environment variable
+// (upper case, with other characters replaced by _), a file named id in
+// SecretsDir, the values file at ValuesPath, and the input's default.
+// ${env:NAME}, ${workspaceFolder}, ${workspaceFolderBasename} and ${userHome}
+// are also supported. ${env:NAME} in headers is left for the client to read
+// when it connects. The zero Resolver reads no environment variables.
+type Resolver struct {
+	// SecretsDir holds one file per input, as mounted Kubernetes secrets do.
+	SecretsDir string
+	// ValuesPath is a YAML or JSON file mapping input ids to values.
+	ValuesPath string
+	// WorkspaceFolder replaces ${workspaceFolder}.
+	WorkspaceFolder string
+	// LookupEnv supplies ${env:NAME} and INPUT_<ID> values.
+	// If nil, no variables are set.
+	LookupEnv func(name string) (string, bool)
+}
+
+// EnvResolver returns the resolver configured by the SECRETS_DIR, VALUES_PATH
+// and WORKSPACE_FOLDER environment variables, which looks up placeholders in
+// the process environment. The workspace folder defaults to the working directory.
+func EnvResolver() Resolver {
+	r := Resolver{
+		SecretsDir:      os.Getenv("SECRETS_DIR"),
+		ValuesPath:      os.Getenv("VALUES_PATH"),
+		WorkspaceFolder: os.Getenv("WORKSPACE_FOLDER"),
+		LookupEnv:       os.LookupEnv,
+	}
+	if r.WorkspaceFolder == "" {
+		r.WorkspaceFolder, _ = os.Getwd()
+	}
+	return r
+}
+
+// placeholder matches ${...} in server settings.
+var placeholder = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// resolution resolves the placeholders of one config.
+type resolution struct {
+	Resolver
+	inputs map[string]Input
+	values map[string]string
+}
+
+func (r Resolver) resolution(inputs []Input) (*resolution, error) {
+	res := &resolution{
+		Resolver: r,
+		inputs:   make(map[string]Input, len(inputs)),
+	}
+	for _, input := range inputs {
+		res.inputs[input.ID] = input
+	}
+
+	if r.ValuesPath != "" {
+		data, err := os.ReadFile(r.ValuesPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read values file: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &res.values); err != nil {
+			return nil, fmt.Errorf("failed to parse values file: %w", err)
+		}
+	}
+	return res, nil
+}
+
+// expand resolves the placeholders in s. Placeholders it does not know are
+// errors, unless deferred, in which case they are left for the client to
+// resolve when it connects, as are ${env:NAME} references.
+func (r *resolution) expand(s string, deferred bool) (string, error) {
+	var errs []error
+	value := placeholder.ReplaceAllStringFunc(s, func(match string) string {
+		ref := match[2 : len(match)-1]
+		if deferred && strings.HasPrefix(ref, "env:") {
+			return match
+		}
+
+		resolved, ok, err := r.resolve(ref)
+		switch {
+		case err != nil:
+			errs = append(errs, err)
+		case !ok && deferred:
+			return match
+		case !ok:
+			errs = append(errs, fmt.Errorf("unsupported placeholder %s", match))
+		}
+		return resolved
+	})
+	return value, errors.Join(errs...)
+}
+
+// resolve returns a placeholder's value, and whether it is one chimera resolves at load time.
+func (r *resolution) resolve(ref string) (string, bool, error) {
+	if id, ok := strings.CutPrefix(ref, "input:"); ok {
+		value, err := r.input(id)
+		return value, true, err
+	}
+
+	if name, ok := strings.CutPrefix(ref, "env:"); ok {
+		value, exists := r.lookupEnv(name)
+		if !exists {
+			return "", true, fmt.Errorf("environment variable %q is not set", name)
+		}
+		return value, true, nil
+	}
+
+	switch ref {
+	case "workspaceFolder", "workspaceFolderBasename":
+		if r.WorkspaceFolder == "" {
+			return "", true, errors.New("workspace folder is not set")
+		}
+		if ref == "workspaceFolderBasename" {
+			return filepath.Base(r.WorkspaceFolder), true, nil
+		}
+		return r.WorkspaceFolder, true, nil
+	case "userHome":
+		home, err := os.UserHomeDir()
+		return home, true, err
+	}
+	return "", false, nil
+}
+
+func (r *resolution) lookupEnv(name string) (string, bool) {
+	if r.LookupEnv == nil {
+		return "", false
+	}
+	return r.LookupEnv(name)
+}
+
+func (r *resolution) input(id string) (string, error) {
+	input, ok := r.inputs[id]
+	if !ok {
+		return "", fmt.Errorf("input %q is not declared in inputs", id)
+	}
+
+	if value, ok := r.lookupEnv(inputEnv(id)); ok {
+		return value, nil
+	}
+
+	if r.SecretsDir != "" {
+		data, err := os.ReadFile(filepath.Join(r.SecretsDir, id))
+		if err == nil {
+			return strings.TrimRight(string(data), "\r\n"), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("failed to read secret for input %q: %w", id, err)
+		}
+	}
+
+	if value, ok := r.values[id]; ok {
+		return value, nil
+	}
+
+	if input.Default != "" {
+		return input.Default, nil
+	}
+	return "", fmt.Errorf("no value for input %q; set %s, or add it to the secrets directory or values file", id, inputEnv(id))
+}
+
+// inputEnv returns the environment variable that supplies an input.
+func inputEnv(id string) string {
+	return "INPUT_" + strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, id)
+}
+
+// expandTree resolves placeholders in every string of a decoded JSON value.
+// Strings under a "headers" key are deferred, since HTTP clients resolve
+// caller references when they connect.
+func (r *resolution) expandTree(v any, path string, deferred bool) (any, error) {
+	switch v := v.(type) {
+	case string:
+		value, err := r.expand(v, deferred)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return value, nil
+
+	case map[string]any:
+		var errs []error
+		for key, child := range v {
+			value, err := r.expandTree(child, path+"."+key, deferred || key == "headers")
+			errs = append(errs, err)
+			v[key] = value
+		}
+		return v, errors.Join(errs...)
+
+	case []any:
+		var errs []error
+		for i, child := range v {
+			value, err := r.expandTree(child, fmt.Sprintf("%s[%d]", path, i), deferred)
+			errs = append(errs, err)
+			v[i] = value
+		}
+		return v, errors.Join(errs...)
+
+	default:
+		return v, nil
+	}
+}
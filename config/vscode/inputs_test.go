@@ -0,0 +1,123 @@
+package vscode
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const inputsConfig = `{
+	"inputs": [
+		{"type": "promptString", "id": "api-key", "password": true},
+		{"type": "promptString", "id": "token"},
+		{"type": "pickString", "id": "region", "default": "eu"},
+		{"type": "promptString", "id": "team"}
+	],
+	"servers": {
+		"fs": {
+			"type": "stdio",
+			"command": "npx",
+			"args": ["server-filesystem", "${workspaceFolder}/data"],
+			"env": {"API_KEY": "${input:api-key}", "HOME_DIR": "${env:VSCODE_TEST_HOME}"}
+		},
+		"api": {
+			"type": "http",
+			"url": "https://${input:region}.example/mcp",
+			"headers": {
+				"Authorization": "Bearer ${input:token}",
+				"X-Team": "${input:team}",
+				"X-Caller": "${header:Authorization}",
+				"X-Home": "${env:VSCODE_TEST_HOME}"
+			}
+		}
+	}
+}`
+
+func TestParseInputs(t *testing.T) {
+	t.Setenv("VSCODE_TEST_HOME", "/home/test")
+	t.Setenv("INPUT_API_KEY", "from-env")
+
+	secrets := t.TempDir()
+	if err := os.WriteFile(filepath.Join(secrets, "token"), []byte("from-secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	values := filepath.Join(t.TempDir(), "values.yaml")
+	if err := os.WriteFile(values, []byte("team: from-values\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := Parse([]byte(inputsConfig), Resolver{
+		SecretsDir:      secrets,
+		ValuesPath:      values,
+		WorkspaceFolder: "/workspace",
+		LookupEnv:       os.LookupEnv,
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+
+	fs := config.Servers["fs"]
+	if fs.Args[1] != "/workspace/data" || fs.Env["API_KEY"] != "from-env" || fs.Env["HOME_DIR"] != "/home/test" {
+		t.Errorf("Unexpected stdio server %+v", fs)
+	}
+
+	api := config.Servers["api"]
+	if api.URL != "https://eu.example/mcp" {
+		t.Errorf("Expected default input in URL, got %q", api.URL)
+	}
+	want := map[string]string{
+		"Authorization": "Bearer from-secret",
+		"X-Team":        "from-values",
+		// Caller references are left for the client
+		"X-Caller": "${header:Authorization}",
+		"X-Home":   "${env:VSCODE_TEST_HOME}",
+	}
+	for key, value := range want {
+		if api.Headers[key] != value {
+			t.Errorf("Expected header %s %q, got %q", key, value, api.Headers[key])
+		}
+	}
+}
+
+func TestParseUnresolved(t *testing.T) {
+	tests := map[string]string{
+		`{"servers": {"s": {"command": "${input:missing}"}}}`:                        `input "missing" is not declared`,
+		`{"inputs": [{"id": "key"}], "servers": {"s": {"command": "${input:key}"}}}`: `no value for input "key"`,
+		`{"servers": {"s": {"args": ["${env:VSCODE_TEST_UNSET}"]}}}`:                 `servers.s.args[0]: environment variable "VSCODE_TEST_UNSET" is not set`,
+		`{"servers": {"s": {"command": "${header:Authorization}"}}}`:                 `unsupported placeholder ${header:Authorization}`,
+		`{"servers": {"s": {"args": ["${workspaceFolder}/src"]}}}`:                   `servers.s.args[0]: workspace folder is not set`,
+		`{"servers": {"s": {"args": ["${workspaceFolderBasename}"]}}}`:               `servers.s.args[0]: workspace folder is not set`,
+	}
+
+	for config, want := range tests {
+		_, err := Parse([]byte(config), Resolver{})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%s): expected error containing %q, got %v", config, want, err)
+		}
+	}
+}
+
+func TestParseIgnoresEnv(t *testing.T) {
+	t.Setenv("INPUT_KEY", "from-env")
+	t.Setenv("VSCODE_TEST_HOME", "/home/test")
+	const data = `{"inputs": [{"id": "key"}], "servers": {"s": {"command": "${input:key}", "args": ["${env:VSCODE_TEST_HOME}"]}}}`
+
+	// Decoding alone resolves nothing
+	var config Config
+	if err := json.Unmarshal([]byte(data), &config); err != nil {
+		t.Fatalf("Failed to decode config: %v", err)
+	}
+	if s := config.Servers["s"]; s.Command != "${input:key}" || s.Args[0] != "${env:VSCODE_TEST_HOME}" {
+		t.Errorf("Expected placeholders to be left as they are, got %+v", s)
+	}
+
+	// Nor does the zero Resolver read the environment
+	_, err := Parse([]byte(data), Resolver{})
+	for _, want := range []string{`no value for input "key"`, `environment variable "VSCODE_TEST_HOME" is not set`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error containing %q, got %v", want, err)
+		}
+	}
+}
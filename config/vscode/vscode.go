@@ -1,17 +1,26 @@
 package vscode
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 
 	"github.com/njayp/chimera/clients/stdio"
 	"github.com/njayp/chimera/clients/stream"
+	"github.com/njayp/chimera/config"
 	"github.com/njayp/chimera/proxy"
 )
 
 // Config is the structure of a VSCode MCP configuration file.
 type Config struct {
 	Servers map[string]Server `json:"servers"`
+	Inputs  []Input           `json:"inputs,omitempty"`
+
+	// Naming is the default naming strategy for servers that do not set one.
+	Naming proxy.Naming `json:"naming,omitempty"`
 }
 
 // Server defines a single MCP server (stdio or HTTP).
@@ -22,24 +31,108 @@ type Server struct {
 	Env     map[string]string `json:"env,omitempty"`
 	URL     string            `json:"url,omitempty"`
 	Headers map[string]string `json:"headers,omitempty"`
+
+	// chimera-specific settings
+	proxy.Options
+}
+
+// Parse decodes a config, resolving placeholders in server settings with r.
+// Unresolved placeholders are errors. Decoding a Config with json.Unmarshal
+// instead leaves placeholders as they are.
+func Parse(data []byte, r Resolver) (Config, error) {
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return Config{}, err
+	}
+	if err := config.Resolve(r); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+// Resolve resolves the placeholders in server settings with r.
+// Unresolved placeholders are errors.
+func (c *Config) Resolve(r Resolver) error {
+	res, err := r.resolution(c.Inputs)
+	if err != nil {
+		return err
+	}
+
+	// Resolve a generic copy of the servers, so every string is visited
+	data, err := json.Marshal(c.Servers)
+	if err != nil {
+		return err
+	}
+	var servers any
+	if err := json.Unmarshal(data, &servers); err != nil {
+		return err
+	}
+	if servers, err = res.expandTree(servers, "servers", false); err != nil {
+		return err
+	}
+
+	resolved, err := json.Marshal(servers)
+	if err != nil {
+		return err
+	}
+	c.Servers = nil
+	return json.Unmarshal(resolved, &c.Servers)
+}
+
+// Validate reports every problem with the config, by field path.
+func (c Config) Validate() error {
+	var errs []error
+	options := make(map[string]proxy.Options, len(c.Servers))
+	for _, name := range slices.Sorted(maps.Keys(c.Servers)) {
+		server := c.Servers[name]
+		path := "servers." + name
+		options[name] = server.Options
+
+		switch server.Type {
+		case "stdio":
+			if server.Command == "" {
+				errs = append(errs, config.Field(path+".command", "missing command"))
+			}
+		case "http":
+			errs = append(errs, config.ValidateURL(path+".url", server.URL))
+		case "":
+			errs = append(errs, config.Field(path+".type", "missing type, want stdio or http"))
+		default:
+			errs = append(errs, config.Field(path+".type", "unknown type %q, want stdio or http", server.Type))
+		}
+	}
+
+	errs = append(errs, config.ValidateOptions("servers", options))
+	return errors.Join(errs...)
 }
 
 // ToClients converts the VSCode config format into proxy.ToClients.
 func (c Config) ToClients() proxy.Clients {
 	clients := make(proxy.Clients)
 	for name, server := range c.Servers {
+		var client proxy.Client
 		switch server.Type {
 		case "stdio":
 			env := make([]string, 0, len(server.Env))
 			for key, value := range server.Env {
 				env = append(env, fmt.Sprintf("%s=%s", key, value))
 			}
-			clients[name] = stdio.NewClient(server.Command, server.Args, env)
+			// Sort so that reloading an unchanged server yields an equal client
+			slices.Sort(env)
+			client = stdio.NewClient(server.Command, server.Args, env)
 		case "http":
-			clients[name] = stream.NewClient(server.URL, server.Headers)
+			client = stream.NewClient(server.URL, server.Headers)
 		default:
 			slog.Error("unsupported server type", "name", name, "type", server.Type)
+			continue
 		}
+
+		options := server.Options
+		if options.Naming == "" {
+			options.Naming = c.Naming
+		}
+
+		clients[name] = proxy.WithOptions(client, options)
 	}
 
 	return clients
@@ -0,0 +1,75 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/njayp/chimera/config/mcpservers"
+	"github.com/njayp/chimera/config/vscode"
+	"github.com/njayp/chimera/proxy"
+)
+
+// AutoConfig is a config in any supported format,
+// detected from its top-level servers or mcpServers key.
+type AutoConfig struct {
+	config Config
+}
+
+// UnmarshalJSON detects the format and decodes the config.
+func (c *AutoConfig) UnmarshalJSON(data []byte) error {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+
+	_, isVSCode := keys["servers"]
+	_, isMCPServers := keys["mcpServers"]
+	switch {
+	case isVSCode && isMCPServers:
+		return errors.New("config has both servers and mcpServers, so its format is ambiguous")
+	case isMCPServers:
+		config := mcpservers.Config{}
+		c.config = &config
+		return json.Unmarshal(data, &config)
+	default:
+		config := vscode.Config{}
+		c.config = &config
+		return json.Unmarshal(data, &config)
+	}
+}
+
+// Resolve resolves placeholders in the detected config, if it has any.
+func (c *AutoConfig) Resolve(r vscode.Resolver) error {
+	if config, ok := c.config.(*vscode.Config); ok {
+		return config.Resolve(r)
+	}
+	return nil
+}
+
+// Validate validates the detected config.
+func (c AutoConfig) Validate() error {
+	if c.config == nil {
+		return nil
+	}
+	return c.config.Validate()
+}
+
+// ToClients converts the detected config into proxy.Clients.
+func (c AutoConfig) ToClients() proxy.Clients {
+	if c.config == nil {
+		return proxy.Clients{}
+	}
+	return c.config.ToClients()
+}
+
+// NewMCPServersWatcher creates a new Watcher for mcpServers configuration files,
+// as used by Claude Desktop and Cursor.
+func NewMCPServersWatcher(ctx context.Context, path string) (*Watcher[mcpservers.Config], error) {
+	return New[mcpservers.Config](ctx, path)
+}
+
+// NewAutoWatcher creates a new Watcher for configuration files in any supported format.
+func NewAutoWatcher(ctx context.Context, path string) (*Watcher[AutoConfig], error) {
+	return New[AutoConfig](ctx, path)
+}
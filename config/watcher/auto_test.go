@@ -0,0 +1,28 @@
+package watcher
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAutoConfig(t *testing.T) {
+	tests := map[string]string{
+		"vscode":     `{"servers": {"a": {"type": "http", "url": "http://localhost:8080"}}}`,
+		"mcpServers": `{"mcpServers": {"a": {"url": "http://localhost:8080"}}}`,
+	}
+
+	for format, data := range tests {
+		var config AutoConfig
+		if err := json.Unmarshal([]byte(data), &config); err != nil {
+			t.Fatalf("%s: failed to parse config: %v", format, err)
+		}
+		if clients := config.ToClients(); len(clients) != 1 || clients["a"] == nil {
+			t.Errorf("%s: expected client a, got %v", format, clients)
+		}
+	}
+
+	var config AutoConfig
+	if err := json.Unmarshal([]byte(`{"servers": {}, "mcpServers": {}}`), &config); err == nil {
+		t.Error("Expected ambiguous config to be rejected")
+	}
+}
@@ -0,0 +1,66 @@
+package watcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/tailscale/hujson"
+	"sigs.k8s.io/yaml"
+)
+
+// Decoder converts a config file's contents to JSON, so Config types,
+// and their UnmarshalJSON methods, work unchanged in any format.
+type Decoder func(data []byte) ([]byte, error)
+
+// Supported formats.
+var (
+	// JSON accepts strict JSON only.
+	JSON Decoder = func(data []byte) ([]byte, error) {
+		return data, nil
+	}
+	// JSONC accepts JSON with comments and trailing commas, as VS Code does.
+	JSONC Decoder = hujson.Standardize
+	// YAML accepts YAML, including plain JSON.
+	YAML Decoder = yaml.YAMLToJSON
+	// TOML accepts TOML.
+	TOML Decoder = func(data []byte) ([]byte, error) {
+		var v map[string]any
+		if err := toml.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		return json.Marshal(v)
+	}
+)
+
+// DecoderFor picks a decoder by file extension: .yaml and .yml are YAML,
+// .toml is TOML, and everything else is JSONC, which also accepts JSON.
+func DecoderFor(path string) Decoder {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return YAML
+	case ".toml":
+		return TOML
+	default:
+		return JSONC
+	}
+}
+
+// ParseFormat returns the decoder for a format name:
+// json, jsonc, yaml or toml.
+func ParseFormat(name string) (Decoder, error) {
+	switch strings.ToLower(name) {
+	case "json":
+		return JSON, nil
+	case "jsonc":
+		return JSONC, nil
+	case "yaml", "yml":
+		return YAML, nil
+	case "toml":
+		return TOML, nil
+	default:
+		return nil, fmt.Errorf("unknown config format %q", name)
+	}
+}
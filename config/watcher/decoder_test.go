@@ -0,0 +1,55 @@
+package watcher
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecoders(t *testing.T) {
+	tests := map[string]string{
+		"config.json": `{"servers": {"a": {"type": "http", "url": "http://localhost:8080"}}}`,
+		"config.jsonc": `{
+			// comments and trailing commas, as VS Code allows
+			"servers": {
+				"a": {"type": "http", "url": "http://localhost:8080",},
+			},
+		}`,
+		"config.yaml": `
+servers:
+  a:
+    type: http
+    url: http://localhost:8080
+`,
+		"config.toml": `
+[servers.a]
+type = "http"
+url = "http://localhost:8080"
+`,
+	}
+
+	for path, data := range tests {
+		decoded, err := DecoderFor(path)([]byte(data))
+		if err != nil {
+			t.Fatalf("%s: failed to decode: %v", path, err)
+		}
+
+		var config AutoConfig
+		if err := json.Unmarshal(decoded, &config); err != nil {
+			t.Fatalf("%s: failed to parse: %v", path, err)
+		}
+		if clients := config.ToClients(); len(clients) != 1 || clients["a"] == nil {
+			t.Errorf("%s: expected client a, got %v", path, clients)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, name := range []string{"json", "JSONC", "yaml", "yml", "toml"} {
+		if _, err := ParseFormat(name); err != nil {
+			t.Errorf("ParseFormat(%q): %v", name, err)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("Expected unknown format to be rejected")
+	}
+}
@@ -0,0 +1,66 @@
+package watcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/njayp/chimera/config"
+)
+
+// duplicateKeys reports keys that appear more than once in the same object.
+func duplicateKeys(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var errs []error
+	if err := walk(dec, "", &errs); err != nil {
+		// Syntax errors are reported when the config is parsed
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// walk consumes one JSON value, recording duplicate keys under path.
+func walk(dec *json.Decoder, path string, errs *[]error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		seen := make(map[string]bool)
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := tok.(string)
+
+			child := key
+			if path != "" {
+				child = path + "." + key
+			}
+			if seen[key] {
+				*errs = append(*errs, config.Field(child, "duplicate key"))
+			}
+			seen[key] = true
+
+			if err := walk(dec, child, errs); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+		return err
+
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			if err := walk(dec, fmt.Sprintf("%s[%d]", path, i), errs); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+		return err
+	}
+	return nil
+}
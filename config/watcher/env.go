@@ -0,0 +1,147 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// DefaultEnvPrefix is the default prefix of the variables read by EnvSource.
+const DefaultEnvPrefix = "CHIMERA_SERVER_"
+
+// EnvSource builds a config from environment variables named
+// <Prefix><NAME>_<SETTING>, such as CHIMERA_SERVER_GITHUB_URL.
+// Settings are URL, COMMAND, ARGS (split on whitespace), CWD, TRANSPORT,
+// HEADER_<NAME> and ENV_<NAME>. Server names are lower cased, with
+// underscores turned into dashes, and header names are canonicalized,
+// so CHIMERA_SERVER_API_SERVER_HEADER_X_API_KEY sets the X-Api-Key header
+// of api-server. The setting starts at the first _HEADER_ or _ENV_, so
+// server names cannot contain those words.
+//
+// The servers are presented as an mcpServers file, so they decode with
+// AutoConfig or mcpservers.Config. The environment never changes, so Watch
+// never reports a change.
+type EnvSource struct {
+	// Prefix defaults to DefaultEnvPrefix.
+	Prefix string
+	// Environ defaults to os.Environ.
+	Environ func() []string
+}
+
+// envServer is a server in the mcpServers format.
+type envServer struct {
+	URL       string            `json:"url,omitempty"`
+	Command   string            `json:"command,omitempty"`
+	Args      []string          `json:"args,omitempty"`
+	Cwd       string            `json:"cwd,omitempty"`
+	Transport string            `json:"transport,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Read collects the servers from the environment.
+func (s EnvSource) Read(context.Context) (map[string][]byte, error) {
+	prefix, environ := s.Prefix, s.Environ
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	if environ == nil {
+		environ = os.Environ
+	}
+
+	var errs []error
+	servers := make(map[string]*envServer)
+	for _, kv := range environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		rest, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+
+		name, setting, ok := cutSetting(rest)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: expected %s<NAME>_<SETTING>", key, prefix))
+			continue
+		}
+		name = strings.ReplaceAll(strings.ToLower(name), "_", "-")
+
+		server, ok := servers[name]
+		if !ok {
+			server = &envServer{}
+			servers[name] = server
+		}
+
+		switch {
+		case strings.HasPrefix(setting, "HEADER_"):
+			if server.Headers == nil {
+				server.Headers = make(map[string]string)
+			}
+			header := strings.ReplaceAll(strings.TrimPrefix(setting, "HEADER_"), "_", "-")
+			server.Headers[http.CanonicalHeaderKey(header)] = value
+		case strings.HasPrefix(setting, "ENV_"):
+			if server.Env == nil {
+				server.Env = make(map[string]string)
+			}
+			server.Env[strings.TrimPrefix(setting, "ENV_")] = value
+		case setting == "URL":
+			server.URL = value
+		case setting == "COMMAND":
+			server.Command = value
+		case setting == "ARGS":
+			server.Args = strings.Fields(value)
+		case setting == "CWD":
+			server.Cwd = value
+		case setting == "TRANSPORT":
+			server.Transport = value
+		default:
+			errs = append(errs, fmt.Errorf("%s: unknown setting %s", key, setting))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(map[string]any{"mcpServers": servers})
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{"env.json": data}, nil
+}
+
+// cutSetting splits NAME_SETTING, where the setting is the last word,
+// or else the first HEADER_ or ENV_ and everything after, so names
+// cannot contain those words but header and variable names can.
+func cutSetting(s string) (name, setting string, ok bool) {
+	first := -1
+	for _, marker := range []string{"_HEADER_", "_ENV_"} {
+		if i := strings.Index(s, marker); i > 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+	if first > 0 {
+		return s[:first], s[first+1:], true
+	}
+
+	i := strings.LastIndex(s, "_")
+	if i <= 0 || i == len(s)-1 {
+		return "", "", false
+	}
+	return s[:i], s[i+1:], true
+}
+
+// Watch does nothing, since the environment of a process is fixed.
+func (s EnvSource) Watch(context.Context, func()) error {
+	return nil
+}
+
+func (s EnvSource) String() string {
+	prefix := s.Prefix
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	return "env:" + prefix + "*"
+}
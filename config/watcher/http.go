@@ -0,0 +1,164 @@
+package watcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+)
+
+// DefaultHTTPPoll is the default interval between requests of an HTTPSource.
+const DefaultHTTPPoll = 30 * time.Second
+
+// HTTPSource fetches a config from an HTTP(S) URL, and polls it for changes.
+// Polls are conditional on the ETag of the last response, so unchanged
+// configs are not downloaded again.
+type HTTPSource struct {
+	URL string
+	// Header is sent with every request, such as for authentication.
+	Header http.Header
+	// Poll is the interval between requests, defaulting to DefaultHTTPPoll.
+	Poll time.Duration
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+
+	mu   sync.Mutex
+	etag string
+	// name and body are from the last successful response
+	name string
+	body []byte
+}
+
+// NewHTTPSource creates a source for the config at rawURL.
+func NewHTTPSource(rawURL string) *HTTPSource {
+	return &HTTPSource{URL: rawURL}
+}
+
+// Read returns the last config fetched, fetching it if there is none yet.
+func (s *HTTPSource) Read(ctx context.Context) (map[string][]byte, error) {
+	s.mu.Lock()
+	fetched := s.body != nil
+	s.mu.Unlock()
+
+	if !fetched {
+		if _, err := s.fetch(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return map[string][]byte{s.name: s.body}, nil
+}
+
+// Watch polls the URL, calling onChange when the config changes.
+func (s *HTTPSource) Watch(ctx context.Context, onChange func()) error {
+	interval := s.Poll
+	if interval <= 0 {
+		interval = DefaultHTTPPoll
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				changed, err := s.fetch(ctx)
+				if err != nil {
+					slog.Error("failed to fetch config", "url", s.URL, "error", err)
+					continue
+				}
+				if changed {
+					onChange()
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *HTTPSource) String() string {
+	return s.URL
+}
+
+// fetch requests the config, and reports whether it changed.
+func (s *HTTPSource) fetch(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return false, err
+	}
+	for key, values := range s.Header {
+		req.Header[key] = values
+	}
+
+	s.mu.Lock()
+	if s.etag != "" {
+		req.Header.Set("If-None-Match", s.etag)
+	}
+	s.mu.Unlock()
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	switch res.StatusCode {
+	case http.StatusNotModified:
+		return false, nil
+	case http.StatusOK:
+	default:
+		return false, fmt.Errorf("unexpected status %s", res.Status)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Servers without ETags send the config every time
+	changed := s.body == nil || !bytes.Equal(body, s.body)
+	s.etag = res.Header.Get("ETag")
+	s.name = fileName(s.URL, res.Header.Get("Content-Type"))
+	s.body = body
+	return changed, nil
+}
+
+// fileName names the file fetched from rawURL, giving it an extension that
+// selects its decoder when the URL lacks one.
+func fileName(rawURL, contentType string) string {
+	name := "config"
+	if u, err := url.Parse(rawURL); err == nil && path.Base(u.Path) != "/" && path.Base(u.Path) != "." {
+		name = path.Base(u.Path)
+	}
+	if path.Ext(name) != "" {
+		return name
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return name + ".yaml"
+	case "application/toml", "text/toml":
+		return name + ".toml"
+	default:
+		return name + ".json"
+	}
+}
@@ -0,0 +1,197 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/njayp/chimera/config/vscode"
+	"github.com/njayp/chimera/proxy"
+)
+
+// normalize applies defaults and rejects unknown settings.
+func (o Options) normalize() (Options, error) {
+	switch o.Conflict {
+	case "":
+		o.Conflict = ConflictError
+	case ConflictError, ConflictFirstWins, ConflictLastWins:
+	default:
+		return o, fmt.Errorf("unknown conflict rule %q", o.Conflict)
+	}
+	return o, nil
+}
+
+// Load reads, validates and merges the config at path, which may be a file,
+// directory or glob as for Watcher, without watching it.
+// It reports every problem it finds, prefixed by the file it is in.
+func Load[T Config](path string, opts Options) (proxy.Clients, error) {
+	return LoadSource[T](context.Background(), FileSource{Path: path}, opts)
+}
+
+// LoadSource reads, validates and merges the config from src, without
+// watching it. It reports every problem it finds, prefixed by the file it is in.
+func LoadSource[T Config](ctx context.Context, src Source, opts Options) (proxy.Clients, error) {
+	opts, err := opts.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse what could be read, to report every problem at once
+	files, err := src.Read(ctx)
+	errs := []error{err}
+	names := slices.Sorted(maps.Keys(files))
+	loaded := make([]proxy.Clients, len(names))
+	for i, name := range names {
+		decoder := opts.Decoder
+		if decoder == nil {
+			decoder = DecoderFor(name)
+		}
+		loaded[i], err = parse[T](files[name], decoder, opts.Resolver)
+		errs = append(errs, inFile(name, err))
+	}
+	if err := errors.Join(errs...); err != nil {
+		// Keep the previous servers if any file is broken,
+		// rather than dropping the servers it defines
+		return nil, err
+	}
+
+	return merge(names, loaded, opts.Conflict)
+}
+
+// isGlob reports whether path is a pattern rather than a file or directory.
+func isGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// configFiles returns the files to load, in merge order.
+func configFiles(path string) ([]string, error) {
+	if isGlob(path) {
+		// Glob sorts its matches
+		return filepath.Glob(path)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	// ReadDir sorts its entries
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		// Skip hidden files, such as editor swap files and the
+		// ..data links of mounted ConfigMaps
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		files = append(files, filepath.Join(path, entry.Name()))
+	}
+	return files, nil
+}
+
+// resolvable is a Config with placeholders to resolve once decoded.
+type resolvable interface {
+	Resolve(r vscode.Resolver) error
+}
+
+// parse decodes, resolves and validates one file.
+func parse[T Config](data []byte, decoder Decoder, r vscode.Resolver) (proxy.Clients, error) {
+	data, err := decoder(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode config file: %w", err)
+	}
+
+	// Unmarshal keeps only the last of duplicate keys, so look for them first
+	duplicates := duplicateKeys(data)
+
+	config := new(T)
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, errors.Join(duplicates, fmt.Errorf("failed to parse config: %w", err))
+	}
+	if config, ok := any(config).(resolvable); ok {
+		if err := config.Resolve(r); err != nil {
+			return nil, errors.Join(duplicates, err)
+		}
+	}
+	if err := errors.Join(duplicates, (*config).Validate()); err != nil {
+		return nil, err
+	}
+	return (*config).ToClients(), nil
+}
+
+// merge combines the clients of several files according to the conflict rule.
+func merge(files []string, loaded []proxy.Clients, conflict Conflict) (proxy.Clients, error) {
+	clients := make(proxy.Clients)
+	origins := make(map[string]string)
+
+	var errs []error
+	for i, file := range files {
+		// Visit servers in a stable order so errors are reproducible
+		for _, name := range slices.Sorted(maps.Keys(loaded[i])) {
+			if origin, ok := origins[name]; ok {
+				switch conflict {
+				case ConflictFirstWins:
+					continue
+				case ConflictError:
+					errs = append(errs, fmt.Errorf("server %q is defined in both %s and %s", name, origin, file))
+					continue
+				}
+			}
+			clients[name] = loaded[i][name]
+			origins[name] = file
+		}
+	}
+
+	// Each file checks its own prefixes, so only those of different files can clash
+	owners := make(map[string]string)
+	for _, name := range slices.Sorted(maps.Keys(clients)) {
+		prefix := proxy.Prefix(name, clients[name])
+		if owner, ok := owners[prefix]; ok {
+			errs = append(errs, fmt.Errorf("%s: server %q: prefix %q is already used by %q in %s",
+				origins[name], name, prefix, owner, origins[owner]))
+			continue
+		}
+		owners[prefix] = name
+	}
+	return clients, errors.Join(errs...)
+}
+
+// inFile prefixes each of the errors joined in err with the file they are in.
+func inFile(file string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []error
+		for _, err := range joined.Unwrap() {
+			errs = append(errs, inFile(file, err))
+		}
+		return errors.Join(errs...)
+	}
+	return fmt.Errorf("%s: %w", file, err)
+}
+
+// messages flattens joined errors into one message each.
+func messages(err error) []string {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var msgs []string
+		for _, err := range joined.Unwrap() {
+			msgs = append(msgs, messages(err)...)
+		}
+		return msgs
+	}
+	return []string{err.Error()}
+}
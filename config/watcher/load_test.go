@@ -0,0 +1,125 @@
+package watcher
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/njayp/chimera/config/vscode"
+)
+
+func TestLoadReportsAllProblems(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mcp.json")
+	data := `{"servers": {
+		"a": {"type": "stdio"},
+		"b": {"type": "http", "url": "ftp://example.com"},
+		"c": {"type": "socket"},
+		"d": {"type": "http", "url": "http://localhost", "alias": "a"},
+		"e": {"type": "stdio", "command": "x", "logLevel": "loud"}
+	}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load[vscode.Config](path, Options{})
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, want := range []string{
+		path + ": servers.a.command",
+		path + ": servers.b.url",
+		path + ": servers.c.type",
+		path + ": servers.d: prefix \"a\"",
+		path + ": servers.e.logLevel",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in:\n%v", want, err)
+		}
+	}
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	err := duplicateKeys([]byte(`{"servers": {"fs": {}, "web": {"env": {"A": "1", "A": "2"}}, "fs": {}}}`))
+	if err == nil {
+		t.Fatal("expected duplicate keys to be reported")
+	}
+	for _, want := range []string{"servers.fs: duplicate key", "servers.web.env.A: duplicate key"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+
+	if err := duplicateKeys([]byte(`{"a": [{"b": 1}, {"b": 2}]}`)); err != nil {
+		t.Errorf("expected no duplicates across array elements, got %v", err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mcp.json")
+	writeServers(t, path, "a")
+
+	w, err := New[vscode.Config](t.Context(), path)
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+	if status := w.Status(); len(status.Servers) != 1 || status.Errors != nil || status.LoadedAt.IsZero() {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	// A broken reload keeps the servers and reports why
+	if err := os.WriteFile(path, []byte(`{"servers": {"a": {"type": "http"}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w.update()
+
+	rec := httptest.NewRecorder()
+	w.StatusHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/config", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", rec.Code)
+	}
+
+	var status Status
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Servers) != 1 || len(status.Errors) != 1 || !strings.Contains(status.Errors[0], "servers.a.url") {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if w.Clients()["a"] == nil {
+		t.Error("expected previous servers to be kept")
+	}
+}
+
+func TestLoadResolver(t *testing.T) {
+	t.Setenv("INPUT_HOST", "from-env")
+	path := filepath.Join(t.TempDir(), "mcp.json")
+	data := `{"inputs": [{"id": "host"}], "servers": {"a": {"type": "http", "url": "http://${input:host}/mcp"}}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Header references are left for the client, even without a resolver
+	headers := filepath.Join(t.TempDir(), "mcp.json")
+	data = `{"servers": {"a": {"type": "http", "url": "http://localhost/mcp", "headers": {"Authorization": "Bearer ${env:LOAD_TEST_TOKEN}"}}}}`
+	if err := os.WriteFile(headers, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load[vscode.Config](headers, Options{}); err != nil {
+		t.Errorf("Failed to load config with an env header: %v", err)
+	}
+
+	// Only an explicit resolver reads the environment
+	if _, err := Load[AutoConfig](path, Options{}); err == nil || !strings.Contains(err.Error(), `no value for input "host"`) {
+		t.Errorf("Expected the input to be unresolved, got %v", err)
+	}
+	opts := Options{Resolver: vscode.Resolver{LookupEnv: os.LookupEnv}}
+	if _, err := Load[AutoConfig](path, opts); err != nil {
+		t.Errorf("Failed to load config: %v", err)
+	}
+}
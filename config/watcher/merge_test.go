@@ -0,0 +1,198 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/njayp/chimera/config/vscode"
+	"github.com/njayp/chimera/internal/testutil"
+	"github.com/njayp/chimera/proxy"
+)
+
+func writeServers(t *testing.T, path string, names ...string) {
+	t.Helper()
+
+	servers := ""
+	for i, name := range names {
+		if i > 0 {
+			servers += ","
+		}
+		servers += fmt.Sprintf(`%q: {"type": "http", "url": "http://localhost/%s"}`, name, filepath.Base(path))
+	}
+	if err := os.WriteFile(path, []byte(`{"servers": {`+servers+`}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDirectoryWatcher(t *testing.T) {
+	dir := t.TempDir()
+	writeServers(t, filepath.Join(dir, "10-team-a.json"), "a")
+	writeServers(t, filepath.Join(dir, "20-team-b.json"), "b")
+
+	w, err := New[vscode.Config](t.Context(), dir)
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+	if clients := w.Clients(); len(clients) != 2 {
+		t.Fatalf("expected 2 clients, got %d", len(clients))
+	}
+
+	// Adding and removing files reloads
+	writeServers(t, filepath.Join(dir, "30-team-c.json"), "c")
+	if err := os.Remove(filepath.Join(dir, "10-team-a.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	var clients proxy.Clients
+	reloaded := testutil.Eventually(func() bool {
+		clients = w.Clients()
+		_, ok := clients["a"]
+		return !ok && len(clients) == 2
+	})
+	if !reloaded {
+		t.Errorf("expected clients b and c, got %v", clients)
+	}
+}
+
+func TestGlobWatcher(t *testing.T) {
+	dir := t.TempDir()
+	writeServers(t, filepath.Join(dir, "a.json"), "a")
+	writeServers(t, filepath.Join(dir, "b.yaml"), "b")
+
+	w, err := New[vscode.Config](t.Context(), filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+	if clients := w.Clients(); len(clients) != 1 || clients["a"] == nil {
+		t.Errorf("expected only client a, got %v", clients)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	dir := t.TempDir()
+	first, last := filepath.Join(dir, "1.json"), filepath.Join(dir, "2.json")
+	writeServers(t, first, "shared", "a")
+	writeServers(t, last, "shared", "b")
+
+	files, err := configFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := make([]proxy.Clients, len(files))
+	for i, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded[i], err = parse[vscode.Config](data, JSON, vscode.Resolver{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := merge(files, loaded, ConflictError); err == nil {
+		t.Error("expected conflict to be an error")
+	}
+
+	for rule, want := range map[Conflict]int{ConflictFirstWins: 0, ConflictLastWins: 1} {
+		clients, err := merge(files, loaded, rule)
+		if err != nil {
+			t.Fatalf("%s: %v", rule, err)
+		}
+		if len(clients) != 3 || clients["shared"] != loaded[want]["shared"] {
+			t.Errorf("%s: expected shared from file %d", rule, want+1)
+		}
+	}
+}
+
+func TestReloadReusesUnchangedClients(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mcp.json")
+	writeServers(t, path, "a", "b")
+
+	w, err := New[vscode.Config](t.Context(), path)
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+	before := w.Clients()
+
+	data := `{"servers": {
+		"a": {"type": "http", "url": "http://localhost/mcp.json"},
+		"b": {"type": "http", "url": "http://localhost/changed"},
+		"c": {"type": "stdio", "command": "x", "env": {"B": "2", "A": "1"}}
+	}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w.update()
+	after := w.Clients()
+
+	if after["a"] != before["a"] {
+		t.Error("expected unchanged client a to be reused")
+	}
+	if after["b"] == before["b"] {
+		t.Error("expected changed client b to be replaced")
+	}
+
+	// Env maps are unordered, yet rebuilding c must yield an equal client
+	w.update()
+	if w.Clients()["c"] != after["c"] {
+		t.Error("expected client c to be reused across reloads")
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mcp.json")
+	writeServers(t, path, "a")
+
+	w, err := New[vscode.Config](t.Context(), path)
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	// fsnotify may reload too, so count concurrently
+	var notified atomic.Int32
+	w.Subscribe(ctx, func() { notified.Add(1) })
+
+	// Reloads that change nothing are not announced
+	w.update()
+	if n := notified.Load(); n != 0 {
+		t.Errorf("expected no notification, got %d", n)
+	}
+
+	writeServers(t, path, "a", "b")
+	w.update()
+	if n := notified.Load(); n != 1 {
+		t.Errorf("expected 1 notification, got %d", n)
+	}
+
+	cancel()
+	if !testutil.Eventually(func() bool {
+		w.RLock()
+		defer w.RUnlock()
+		return len(w.subscribers) == 0
+	}) {
+		t.Error("expected the subscription to end with its context")
+	}
+}
+
+func TestPrefixAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	first, last := filepath.Join(dir, "10-a.json"), filepath.Join(dir, "20-gh.json")
+	if err := os.WriteFile(first, []byte(`{"servers": {"a": {"type": "http", "url": "http://localhost/a", "alias": "gh"}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	writeServers(t, last, "gh")
+
+	_, err := Load[vscode.Config](dir, Options{})
+	want := last + `: server "gh": prefix "gh" is already used by "a" in ` + first
+	if err == nil || err.Error() != want {
+		t.Errorf("expected %q, got %v", want, err)
+	}
+}
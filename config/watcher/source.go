@@ -0,0 +1,66 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/njayp/chimera/internal/filewatch"
+)
+
+// Source supplies the files that make up a config, and reports changes to them.
+type Source interface {
+	// Read returns the content of each file, by name. Files are merged in
+	// the lexical order of their names, and decoded by their extension.
+	Read(ctx context.Context) (map[string][]byte, error)
+	// Watch calls onChange whenever the files may have changed, until ctx is done.
+	Watch(ctx context.Context, onChange func()) error
+	// String describes the source, for logs and Status.
+	String() string
+}
+
+// FileSource reads a local file, a directory such as conf.d whose files are
+// merged, or a glob pattern matching the files to merge.
+type FileSource struct {
+	Path string
+	// Debounce and Poll tune change detection, as in Options.
+	Debounce time.Duration
+	Poll     time.Duration
+}
+
+// Read reads every file, reporting all that fail.
+func (s FileSource) Read(context.Context) (map[string][]byte, error) {
+	names, err := configFiles(s.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	files := make(map[string][]byte, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			errs = append(errs, inFile(name, fmt.Errorf("failed to read config file: %w", err)))
+			continue
+		}
+		files[name] = data
+	}
+	return files, errors.Join(errs...)
+}
+
+// Watch watches the files through their directory.
+func (s FileSource) Watch(ctx context.Context, onChange func()) error {
+	return filewatch.Watch(ctx, s.Path, filewatch.Options{
+		Debounce: s.Debounce,
+		Poll:     s.Poll,
+		Files: func() ([]string, error) {
+			return configFiles(s.Path)
+		},
+	}, onChange)
+}
+
+func (s FileSource) String() string {
+	return s.Path
+}
@@ -0,0 +1,155 @@
+package watcher
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/njayp/chimera/internal/testutil"
+)
+
+func TestHTTPSource(t *testing.T) {
+	var mu sync.Mutex
+	config, etag := "mcpServers:\n  a:\n    url: http://localhost/a\n", `"1"`
+	var downloads atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads.Add(1)
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write([]byte(config))
+	}))
+	defer server.Close()
+
+	source := NewHTTPSource(server.URL + "/mcp")
+	source.Header = http.Header{"Authorization": {"Bearer token"}}
+	source.Poll = 20 * time.Millisecond
+
+	w, err := NewFromSource[AutoConfig](t.Context(), source, Options{})
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+	if clients := w.Clients(); len(clients) != 1 || clients["a"] == nil {
+		t.Fatalf("expected client a, got %v", clients)
+	}
+
+	// Unchanged configs are not downloaded again
+	time.Sleep(100 * time.Millisecond)
+	if n := downloads.Load(); n != 1 {
+		t.Errorf("expected 1 download, got %d", n)
+	}
+
+	mu.Lock()
+	config, etag = "mcpServers:\n  b:\n    url: http://localhost/b\n", `"2"`
+	mu.Unlock()
+	if !testutil.Eventually(func() bool {
+		return w.Clients()["b"] != nil
+	}) {
+		t.Fatalf("expected client b after the config changed, got %v", w.Clients())
+	}
+}
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		url, contentType, want string
+	}{
+		{"https://example.com/mcp.toml", "", "mcp.toml"},
+		{"https://example.com/config", "application/yaml; charset=utf-8", "config.yaml"},
+		{"https://example.com/", "application/json", "config.json"},
+		{"https://example.com", "text/plain", "config.json"},
+	}
+	for _, tt := range tests {
+		if got := fileName(tt.url, tt.contentType); got != tt.want {
+			t.Errorf("fileName(%q, %q) = %q, want %q", tt.url, tt.contentType, got, tt.want)
+		}
+	}
+}
+
+func TestEnvSource(t *testing.T) {
+	source := EnvSource{Environ: func() []string {
+		return []string{
+			"CHIMERA_SERVER_GITHUB_URL=https://api.example.com/mcp",
+			"CHIMERA_SERVER_GITHUB_HEADER_AUTHORIZATION=Bearer ${env:GITHUB_TOKEN}",
+			"CHIMERA_SERVER_API_SERVER_URL=http://api-server:8080/mcp",
+			"CHIMERA_SERVER_API_SERVER_HEADER_X_API_KEY=key",
+			"CHIMERA_SERVER_FILES_COMMAND=mcp-filesystem",
+			"CHIMERA_SERVER_FILES_ARGS=start --root /data",
+			"CHIMERA_SERVER_FILES_ENV_LOG_LEVEL=debug",
+			"PATH=/usr/bin",
+		}
+	}}
+
+	files, err := source.Read(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := string(files["env.json"])
+	for _, want := range []string{
+		`"api-server":{"url":"http://api-server:8080/mcp","headers":{"X-Api-Key":"key"}}`,
+		`"args":["start","--root","/data"]`,
+		`"env":{"LOG_LEVEL":"debug"}`,
+		`"Authorization":"Bearer ${env:GITHUB_TOKEN}"`,
+	} {
+		if !strings.Contains(data, want) {
+			t.Errorf("expected %s in %s", want, data)
+		}
+	}
+
+	clients, err := LoadSource[AutoConfig](t.Context(), source, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clients) != 3 || clients["files"] == nil {
+		t.Errorf("expected github, api-server and files, got %v", clients)
+	}
+
+	bad := EnvSource{Prefix: "X_", Environ: func() []string {
+		return []string{"X_GITHUB_PORT=80", "X_URL=http://localhost"}
+	}}
+	if _, err := bad.Read(t.Context()); err == nil ||
+		!strings.Contains(err.Error(), "X_GITHUB_PORT: unknown setting PORT") ||
+		!strings.Contains(err.Error(), "X_URL: expected X_<NAME>_<SETTING>") {
+		t.Errorf("expected errors for both variables, got %v", err)
+	}
+}
+
+func TestCutSetting(t *testing.T) {
+	tests := []struct {
+		in            string
+		name, setting string
+		ok            bool
+	}{
+		{"GITHUB_URL", "GITHUB", "URL", true},
+		{"API_SERVER_URL", "API_SERVER", "URL", true},
+		{"API_SERVER_HEADER_X_API_KEY", "API_SERVER", "HEADER_X_API_KEY", true},
+		{"FILES_ENV_LOG_LEVEL", "FILES", "ENV_LOG_LEVEL", true},
+		// The first marker wins, whatever its kind
+		{"SRV_ENV_A_HEADER_B", "SRV", "ENV_A_HEADER_B", true},
+		{"SRV_HEADER_A_ENV_B", "SRV", "HEADER_A_ENV_B", true},
+		{"URL", "", "", false},
+		{"GITHUB_", "", "", false},
+		{"_URL", "", "", false},
+	}
+
+	for _, tt := range tests {
+		name, setting, ok := cutSetting(tt.in)
+		if name != tt.name || setting != tt.setting || ok != tt.ok {
+			t.Errorf("cutSetting(%q) = %q, %q, %v, want %q, %q, %v", tt.in, name, setting, ok, tt.name, tt.setting, tt.ok)
+		}
+	}
+}
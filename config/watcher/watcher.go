@@ -4,36 +4,106 @@ import (
 	"context"
 	"encoding/json"
 	"log/slog"
-	"os"
+	"maps"
+	"net/http"
+	"slices"
 	"sync"
+	"time"
 
-	"github.com/fsnotify/fsnotify"
 	"github.com/njayp/chimera/config/vscode"
 	"github.com/njayp/chimera/proxy"
 )
 
 // Config represents the file structure, and it must be able to produce Clients.
+// Configs that fail validation are not loaded.
 type Config interface {
+	Validate() error
 	ToClients() proxy.Clients
 }
 
-// Watcher watches a configuration file for changes and reloads it.
+// Conflict decides what happens when several files define the same server.
+type Conflict string
+
+// Supported conflict rules. Files are merged in lexical order.
+const (
+	// ConflictError rejects the reload, keeping the previous servers.
+	ConflictError Conflict = "error"
+	// ConflictFirstWins keeps the server from the first file.
+	ConflictFirstWins Conflict = "first"
+	// ConflictLastWins keeps the server from the last file.
+	ConflictLastWins Conflict = "last"
+)
+
+// Options configures a Watcher.
+type Options struct {
+	// Decoder decodes every file, overriding detection by extension.
+	Decoder Decoder
+	// Conflict resolves servers defined in more than one file,
+	// defaulting to ConflictError.
+	Conflict Conflict
+	// Debounce is how long file events must stop before a reload, and
+	// Poll the interval between checks that catch changes events miss.
+	// Zero picks a default, and a negative Poll disables polling.
+	Debounce time.Duration
+	Poll     time.Duration
+	// Resolver resolves placeholders in VS Code configs.
+	// The zero Resolver reads no environment variables.
+	Resolver vscode.Resolver
+}
+
+// Watcher watches configuration files for changes and reloads them.
+// The files come from a Source, by default a FileSource, whose path may be
+// a single file, a directory such as conf.d, whose files are merged, or
+// a glob pattern matching the files to merge.
 // T should not be a pointer.
 type Watcher[T Config] struct {
 	sync.RWMutex
-	path string
+	// ctx bounds reads from the source
+	ctx    context.Context
+	source Source
+	opts   Options
 	// clients are stored so they can be reused
 	clients proxy.Clients
+	status  Status
+	// subscribers are notified when clients change, by subscription id
+	subscribers map[int]func()
+	nextID      int
 }
 
-// New creates a new Watcher.
+// New creates a new Watcher, decoding files according to their extension.
 func New[T Config](ctx context.Context, path string) (*Watcher[T], error) {
+	return NewWithOptions[T](ctx, path, Options{})
+}
+
+// NewWithDecoder creates a new Watcher that decodes files with decoder.
+func NewWithDecoder[T Config](ctx context.Context, path string, decoder Decoder) (*Watcher[T], error) {
+	return NewWithOptions[T](ctx, path, Options{Decoder: decoder})
+}
+
+// NewWithOptions creates a new Watcher.
+func NewWithOptions[T Config](ctx context.Context, path string, opts Options) (*Watcher[T], error) {
+	return NewFromSource[T](ctx, FileSource{
+		Path:     path,
+		Debounce: opts.Debounce,
+		Poll:     opts.Poll,
+	}, opts)
+}
+
+// NewFromSource creates a new Watcher that reads its files from source.
+func NewFromSource[T Config](ctx context.Context, source Source, opts Options) (*Watcher[T], error) {
+	opts, err := opts.normalize()
+	if err != nil {
+		return nil, err
+	}
+
 	w := &Watcher[T]{
-		path: path,
+		ctx:    ctx,
+		source: source,
+		opts:   opts,
 	}
 	// update after starting to avoid race
 	defer w.update()
-	return w, w.start(ctx)
+	return w, source.Watch(ctx, w.update)
 }
 
 // NewVSCodeWatcher creates a new Watcher for VSCode MCP configuration files.
@@ -41,46 +111,6 @@ func NewVSCodeWatcher(ctx context.Context, path string) (*Watcher[vscode.Config]
 	return New[vscode.Config](ctx, path)
 }
 
-func (w *Watcher[T]) start(ctx context.Context) error {
-	// Create new watcher
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		return err
-	}
-
-	// Listen for events
-	go func() {
-		for {
-			select {
-			case event, ok := <-watcher.Events:
-				if !ok {
-					continue
-				}
-				if event.Has(fsnotify.Write) {
-					w.update()
-				}
-				if event.Has(fsnotify.Create) {
-					w.update()
-				}
-			case err, ok := <-watcher.Errors:
-				if !ok {
-					continue
-				}
-				slog.Error(err.Error())
-			case <-ctx.Done():
-				err := watcher.Close()
-				if err != nil {
-					slog.Error("failed to close file watcher", "error", err)
-				}
-				return
-			}
-		}
-	}()
-
-	// Add file to watch
-	return watcher.Add(w.path)
-}
-
 // Clients returns the current set of clients.
 func (w *Watcher[T]) Clients() proxy.Clients {
 	w.RLock()
@@ -88,20 +118,101 @@ func (w *Watcher[T]) Clients() proxy.Clients {
 	return w.clients
 }
 
-func (w *Watcher[T]) update() {
-	data, err := os.ReadFile(w.path)
-	if err != nil {
-		slog.Error("failed to read config file", "error", err)
-		return
+// Subscribe calls notify after each reload that changes the clients,
+// until ctx is done. It implements proxy.Notifier.
+func (w *Watcher[T]) Subscribe(ctx context.Context, notify func()) {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.subscribers == nil {
+		w.subscribers = make(map[int]func())
 	}
+	id := w.nextID
+	w.nextID++
+	w.subscribers[id] = notify
+
+	context.AfterFunc(ctx, func() {
+		w.Lock()
+		defer w.Unlock()
+		delete(w.subscribers, id)
+	})
+}
 
-	config := new(T)
-	if err := json.Unmarshal(data, config); err != nil {
-		slog.Error("failed to parse JSON config", "error", err)
-		return
+func (w *Watcher[T]) update() {
+	if w.reload() {
+		w.RLock()
+		subscribers := slices.Collect(maps.Values(w.subscribers))
+		w.RUnlock()
+
+		for _, notify := range subscribers {
+			notify()
+		}
 	}
+}
+
+// reload loads the config, and reports whether the clients changed.
+func (w *Watcher[T]) reload() bool {
+	clients, err := LoadSource[T](w.ctx, w.source, w.opts)
 
 	w.Lock()
 	defer w.Unlock()
-	w.clients = (*config).ToClients()
+	if err != nil {
+		// Keep the previous servers
+		slog.Error("failed to load config", "source", w.source, "error", err)
+		w.status.Errors = messages(err)
+		w.status.FailedAt = time.Now()
+		return false
+	}
+
+	// Reuse unchanged clients, so their shared backends stay connected.
+	// Subscribers retire the backends of the others.
+	clients, diff := proxy.Reconcile(w.clients, clients)
+	if !diff.Empty() {
+		slog.Info("config reloaded", "source", w.source,
+			"added", diff.Added, "changed", diff.Changed, "removed", diff.Removed)
+	}
+
+	w.clients = clients
+	w.status.Servers = slices.Sorted(maps.Keys(clients))
+	w.status.Errors = nil
+	w.status.LoadedAt = time.Now()
+	return !diff.Empty()
+}
+
+// Status describes the outcome of the latest reload.
+type Status struct {
+	Source string `json:"source"`
+	// Servers are the names of the servers currently loaded.
+	Servers  []string  `json:"servers"`
+	LoadedAt time.Time `json:"loadedAt,omitzero"`
+	// Errors are the problems found by the latest reload, if it failed.
+	// The servers from the last successful reload stay loaded.
+	Errors   []string  `json:"errors,omitempty"`
+	FailedAt time.Time `json:"failedAt,omitzero"`
+}
+
+// Status returns the outcome of the latest reload.
+func (w *Watcher[T]) Status() Status {
+	w.RLock()
+	defer w.RUnlock()
+
+	status := w.status
+	status.Source = w.source.String()
+	return status
+}
+
+// StatusHandler serves the watcher's Status as JSON, for admin endpoints.
+// It responds 503 while the latest reload has failed.
+func (w *Watcher[T]) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		status := w.Status()
+
+		rw.Header().Set("Content-Type", "application/json")
+		if len(status.Errors) > 0 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+		if err := json.NewEncoder(rw).Encode(status); err != nil {
+			slog.Error("failed to write config status", "error", err)
+		}
+	})
 }
@@ -5,19 +5,113 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"maps"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/oauthex"
+	"github.com/njayp/chimera/auth"
+	"github.com/njayp/chimera/config/kube"
+	"github.com/njayp/chimera/config/vscode"
 	"github.com/njayp/chimera/config/watcher"
+	"github.com/njayp/chimera/policy"
 	"github.com/njayp/chimera/proxy"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(validate(os.Args[2:]))
+	}
+
 	if err := run(); err != nil {
 		log.Fatal(err)
 	}
 }
 
+// validate checks config files without serving them, for CI.
+// It prints every problem found and returns the exit code.
+func validate(paths []string) int {
+	if len(paths) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: chimera validate <file|dir|glob|url>...")
+		return 2
+	}
+
+	opts, err := watchOptions()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	code := 0
+	for _, path := range paths {
+		source, err := configSource(path, opts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+
+		clients, err := watcher.LoadSource[watcher.AutoConfig](context.Background(), source, opts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+			continue
+		}
+		fmt.Printf("%s: ok, %d servers\n", path, len(clients))
+	}
+	return code
+}
+
+// watchOptions reads the watcher options from env vars.
+func watchOptions() (watcher.Options, error) {
+	opts := watcher.Options{
+		Conflict: watcher.Conflict(os.Getenv("CONFIG_CONFLICT")),
+		Resolver: vscode.EnvResolver(),
+	}
+	if format, exists := os.LookupEnv("CONFIG_FORMAT"); exists {
+		decoder, err := watcher.ParseFormat(format)
+		if err != nil {
+			return opts, err
+		}
+		opts.Decoder = decoder
+	}
+	if poll, exists := os.LookupEnv("CONFIG_POLL_INTERVAL"); exists {
+		interval, err := time.ParseDuration(poll)
+		if err != nil {
+			return opts, fmt.Errorf("invalid CONFIG_POLL_INTERVAL: %w", err)
+		}
+		opts.Poll = interval
+	}
+	return opts, nil
+}
+
+// configSource picks where the config comes from by the form of path:
+// an http(s) URL, configmap://[namespace/]name, env:[PREFIX],
+// or else a local file, directory or glob.
+func configSource(path string, opts watcher.Options) (watcher.Source, error) {
+	switch {
+	case strings.HasPrefix(path, "http://"), strings.HasPrefix(path, "https://"):
+		source := watcher.NewHTTPSource(path)
+		if opts.Poll > 0 {
+			source.Poll = opts.Poll
+		}
+		return source, nil
+	case strings.HasPrefix(path, "configmap://"):
+		ref := strings.TrimPrefix(path, "configmap://")
+		namespace, name, found := strings.Cut(ref, "/")
+		if !found {
+			namespace, name = "", ref
+		}
+		return kube.InCluster(namespace, name)
+	case strings.HasPrefix(path, "env:"):
+		return watcher.EnvSource{Prefix: strings.TrimPrefix(path, "env:")}, nil
+	default:
+		return watcher.FileSource{Path: path, Debounce: opts.Debounce, Poll: opts.Poll}, nil
+	}
+}
+
 func run() error {
 	// get port and path from env vars
 	port, exists := os.LookupEnv("PORT")
@@ -31,14 +125,114 @@ func run() error {
 	}
 
 	ctx := context.Background()
-	watcher, err := watcher.NewVSCodeWatcher(ctx, path)
+	watchOpts, err := watchOptions()
+	if err != nil {
+		return err
+	}
+
+	// CONFIG_PATH may also be a conf.d directory, a glob, a URL,
+	// a ConfigMap or environment variables
+	source, err := configSource(path, watchOpts)
+	if err != nil {
+		return fmt.Errorf("failed to configure config source: %w", err)
+	}
+	watcher, err := watcher.NewFromSource[watcher.AutoConfig](ctx, source, watchOpts)
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+
+	opts := &proxy.HandlerOptions{}
+	if path, exists := os.LookupEnv("POLICY_PATH"); exists {
+		authorizer, err := policy.NewWatcher(ctx, path)
+		if err != nil {
+			return fmt.Errorf("failed to create policy watcher: %w", err)
+		}
+		opts.Authorizer = authorizer
+	}
+
+	handler, err := authenticate(proxy.HandlerWithOptions(watcher, opts))
 	if err != nil {
-		return fmt.Errorf("failed to create file watcher: %w", err)
+		return fmt.Errorf("failed to configure auth: %w", err)
+	}
+
+	// Serve the config status apart from the authenticated endpoint
+	if addr, exists := os.LookupEnv("ADMIN_ADDR"); exists {
+		mux := http.NewServeMux()
+		mux.Handle("GET /config", watcher.StatusHandler())
+		go func() {
+			log.Printf("Starting admin server on address %q", addr)
+			if err := http.ListenAndServe(addr, mux); err != nil {
+				log.Printf("Admin server stopped: %v", err)
+			}
+		}()
 	}
 
 	// Start HTTP server
 	addr := ":" + port
 	log.Printf("Starting reverse-proxy MCP HTTP server on address %q", addr)
-	handler := proxy.Handler(watcher)
 	return http.ListenAndServe(addr, handler)
 }
+
+// authenticate guards handler with the auth configured by env vars.
+// Without any, the endpoint is left open.
+func authenticate(handler http.Handler) (http.Handler, error) {
+	var verifiers []mcpauth.TokenVerifier
+
+	keys := make(map[string]string)
+	if text, exists := os.LookupEnv("API_KEYS"); exists {
+		parsed, err := auth.ParseAPIKeys(text)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(keys, parsed)
+	}
+	if path, exists := os.LookupEnv("API_KEYS_FILE"); exists {
+		loaded, err := auth.LoadAPIKeys(path)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(keys, loaded)
+	}
+	if len(keys) > 0 {
+		verifiers = append(verifiers, auth.APIKeys(keys))
+	}
+
+	if path, exists := os.LookupEnv("JWKS_PATH"); exists {
+		jwks, err := auth.LoadJWKS(path)
+		if err != nil {
+			return nil, err
+		}
+		// Resource servers must only accept tokens issued for them
+		audience := os.Getenv("JWT_AUDIENCE")
+		if audience == "" {
+			audience = os.Getenv("RESOURCE_URL")
+		}
+		verifiers = append(verifiers, auth.JWT(jwks, os.Getenv("JWT_ISSUER"), audience))
+	}
+
+	if len(verifiers) == 0 {
+		log.Printf("No API_KEYS, API_KEYS_FILE or JWKS_PATH set, serving without authentication")
+		return handler, nil
+	}
+
+	opts := auth.Options{
+		Verifier: auth.Chain(verifiers...),
+		Scopes:   fields(os.Getenv("AUTH_SCOPES")),
+	}
+	if resource, exists := os.LookupEnv("RESOURCE_URL"); exists {
+		opts.Metadata = &oauthex.ProtectedResourceMetadata{
+			Resource:               resource,
+			AuthorizationServers:   fields(os.Getenv("AUTHORIZATION_SERVERS")),
+			ScopesSupported:        opts.Scopes,
+			BearerMethodsSupported: []string{"header"},
+		}
+	}
+	return auth.Handler(handler, opts)
+}
+
+// fields splits a comma or space separated env var.
+func fields(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
@@ -0,0 +1,183 @@
+// Package filewatch reports changes to files, surviving the ways they are
+// replaced in practice: editors that save by renaming over the file, and
+// Kubernetes ConfigMap volumes that swap a ..data symlink.
+package filewatch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Defaults for Options.
+const (
+	DefaultDebounce = 100 * time.Millisecond
+	DefaultPoll     = 10 * time.Second
+)
+
+// Options tunes Watch.
+type Options struct {
+	// Debounce is how long events must stop before the files are checked,
+	// so bursts from one save cause one reload. Zero means DefaultDebounce.
+	Debounce time.Duration
+	// Poll is the interval between checks made regardless of events,
+	// catching changes that fsnotify misses, such as on network volumes.
+	// Zero means DefaultPoll, and a negative value disables polling.
+	Poll time.Duration
+	// Files lists the files whose content is compared, defaulting to the
+	// watched path itself.
+	Files func() ([]string, error)
+}
+
+// Watch calls onChange whenever the content of the files changes, until ctx
+// is done. path may be a file, a directory or a glob pattern; the directory
+// holding the files is watched rather than the files themselves, since
+// replacing a file drops any watch on it.
+func Watch(ctx context.Context, path string, opts Options, onChange func()) error {
+	if opts.Debounce == 0 {
+		opts.Debounce = DefaultDebounce
+	}
+	if opts.Poll == 0 {
+		opts.Poll = DefaultPoll
+	}
+	if opts.Files == nil {
+		opts.Files = func() ([]string, error) {
+			return []string{path}, nil
+		}
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	dir := directory(path)
+	if err := watcher.Add(dir); err != nil {
+		_ = watcher.Close()
+		return err
+	}
+
+	w := &watch{
+		dir:      dir,
+		opts:     opts,
+		watcher:  watcher,
+		onChange: onChange,
+	}
+	w.sum = w.hash()
+	go w.run(ctx)
+	return nil
+}
+
+// directory returns the directory to watch for path.
+func directory(path string) string {
+	if strings.ContainsAny(path, "*?[") {
+		return filepath.Dir(path)
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return path
+	}
+	return filepath.Dir(path)
+}
+
+type watch struct {
+	dir      string
+	opts     Options
+	watcher  *fsnotify.Watcher
+	onChange func()
+	// sum is the hash of the files when last checked
+	sum []byte
+}
+
+func (w *watch) run(ctx context.Context) {
+	defer func() {
+		if err := w.watcher.Close(); err != nil {
+			slog.Error("failed to close file watcher", "error", err)
+		}
+	}()
+
+	debounce := time.NewTimer(w.opts.Debounce)
+	debounce.Stop()
+
+	var poll <-chan time.Time
+	if w.opts.Poll > 0 {
+		ticker := time.NewTicker(w.opts.Poll)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			// Removing or renaming the directory itself drops the watch
+			if event.Name == w.dir && event.Has(fsnotify.Remove|fsnotify.Rename) {
+				w.rewatch()
+			}
+			// Any event may be a change: writes, creates, renames over the
+			// file, symlink swaps and permission changes all count
+			debounce.Reset(w.opts.Debounce)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			slog.Error("file watcher error", "path", w.dir, "error", err)
+		case <-debounce.C:
+			w.check()
+		case <-poll:
+			w.rewatch()
+			w.check()
+		case <-ctx.Done():
+			debounce.Stop()
+			return
+		}
+	}
+}
+
+// rewatch adds the watch again, in case it was dropped.
+func (w *watch) rewatch() {
+	if err := w.watcher.Add(w.dir); err != nil {
+		slog.Debug("failed to watch directory", "path", w.dir, "error", err)
+	}
+}
+
+// check calls onChange if the files changed since the last check.
+func (w *watch) check() {
+	sum := w.hash()
+	if bytes.Equal(sum, w.sum) {
+		return
+	}
+	w.sum = sum
+	w.onChange()
+}
+
+// hash digests the names and contents of the files. Files that cannot be
+// read contribute their error, so that losing or regaining them is a change.
+func (w *watch) hash() []byte {
+	h := sha256.New()
+	files, err := w.opts.Files()
+	if err != nil {
+		h.Write([]byte(err.Error()))
+	}
+	for _, file := range files {
+		h.Write([]byte(file))
+		h.Write([]byte{0})
+
+		// ReadFile follows symlinks, such as those of ConfigMap volumes
+		data, err := os.ReadFile(file)
+		if err != nil {
+			data = []byte(err.Error())
+		}
+		h.Write(data)
+		h.Write([]byte{0})
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,125 @@
+package filewatch
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/njayp/chimera/internal/testutil"
+)
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitFor polls changes until it reaches want, failing after a deadline.
+func waitFor(t *testing.T, changes *atomic.Int32, want int32) {
+	t.Helper()
+	if !testutil.Eventually(func() bool { return changes.Load() >= want }) {
+		t.Fatalf("expected %d changes, got %d", want, changes.Load())
+	}
+}
+
+func watchCount(t *testing.T, path string, opts Options) *atomic.Int32 {
+	t.Helper()
+	var changes atomic.Int32
+	if opts.Debounce == 0 {
+		opts.Debounce = 20 * time.Millisecond
+	}
+	if err := Watch(t.Context(), path, opts, func() { changes.Add(1) }); err != nil {
+		t.Fatalf("failed to watch: %v", err)
+	}
+	return &changes
+}
+
+func TestRenameSave(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mcp.json")
+	write(t, path, "1")
+	changes := watchCount(t, path, Options{})
+
+	// Editors write a temporary file and rename it over the original
+	for i, content := range []string{"2", "3"} {
+		tmp := filepath.Join(dir, ".mcp.json.swp")
+		write(t, tmp, content)
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatal(err)
+		}
+		waitFor(t, changes, int32(i+1))
+	}
+}
+
+func TestConfigMapSwap(t *testing.T) {
+	// Mimic a ConfigMap volume: mcp.json -> ..data/mcp.json, ..data -> ..v1
+	dir := t.TempDir()
+	for _, version := range []string{"..v1", "..v2"} {
+		if err := os.Mkdir(filepath.Join(dir, version), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		write(t, filepath.Join(dir, version, "mcp.json"), version)
+	}
+	if err := os.Symlink("..v1", filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "mcp.json")
+	if err := os.Symlink(filepath.Join("..data", "mcp.json"), path); err != nil {
+		t.Fatal(err)
+	}
+	changes := watchCount(t, path, Options{})
+
+	// Kubernetes swaps the ..data link atomically
+	tmp := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink("..v2", tmp); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, changes, 1)
+}
+
+func TestDebounce(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mcp.json")
+	write(t, path, "0")
+	changes := watchCount(t, path, Options{Debounce: 200 * time.Millisecond})
+
+	for i := range 10 {
+		write(t, path, string(rune('a'+i)))
+	}
+	waitFor(t, changes, 1)
+
+	time.Sleep(400 * time.Millisecond)
+	if n := changes.Load(); n != 1 {
+		t.Errorf("expected a burst to cause 1 change, got %d", n)
+	}
+
+	// Events that leave the content alone are ignored
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(400 * time.Millisecond)
+	if n := changes.Load(); n != 1 {
+		t.Errorf("expected unchanged content to be ignored, got %d changes", n)
+	}
+}
+
+func TestPoll(t *testing.T) {
+	// Compare a file outside the watched directory, which no event reports
+	watched, elsewhere := t.TempDir(), filepath.Join(t.TempDir(), "mcp.json")
+	write(t, elsewhere, "1")
+	changes := watchCount(t, filepath.Join(watched, "mcp.json"), Options{
+		Poll: 50 * time.Millisecond,
+		Files: func() ([]string, error) {
+			return []string{elsewhere}, nil
+		},
+	})
+
+	write(t, elsewhere, "2")
+	waitFor(t, changes, 1)
+}
@@ -0,0 +1,17 @@
+// Package testutil holds helpers shared by the tests of other packages.
+package testutil
+
+import "time"
+
+// Eventually polls cond until it holds or five seconds pass,
+// and reports whether it held.
+func Eventually(cond func() bool) bool {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
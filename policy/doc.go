@@ -0,0 +1,2 @@
+// Package policy authorizes callers by group, from a hot-reloaded YAML or JSON file.
+package policy
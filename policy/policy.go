@@ -0,0 +1,78 @@
+package policy
+
+import (
+	"slices"
+
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/njayp/chimera/auth"
+	"github.com/njayp/chimera/proxy"
+	"sigs.k8s.io/yaml"
+)
+
+// Policy grants groups access to capabilities.
+// A caller may use a capability if any of its groups' roles allows it.
+type Policy struct {
+	// Roles maps group names to what their members may use.
+	Roles map[string]Role `json:"roles"`
+	// Members adds groups to subjects, for callers whose
+	// credentials carry none, such as API keys.
+	Members map[string][]string `json:"members,omitempty"`
+	// Default groups apply to every caller, including unauthenticated ones.
+	Default []string `json:"default,omitempty"`
+}
+
+// Role selects capabilities by their exposed names, and resources by
+// their chimera:// URIs. A role grants only what its include patterns
+// match, so it grants nothing of a kind it leaves out; use include: ["*"]
+// to grant every capability of a kind.
+type Role struct {
+	Tools     proxy.Filter `json:"tools,omitzero"`
+	Prompts   proxy.Filter `json:"prompts,omitzero"`
+	Resources proxy.Filter `json:"resources,omitzero"`
+}
+
+// Parse decodes a YAML or JSON policy.
+func Parse(data []byte) (*Policy, error) {
+	policy := new(Policy)
+	if err := yaml.UnmarshalStrict(data, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// groups returns every group the principal belongs to.
+func (p *Policy) groups(principal *auth.Principal) []string {
+	groups := slices.Clone(p.Default)
+	if principal != nil {
+		groups = append(groups, principal.Groups...)
+		groups = append(groups, p.Members[principal.Subject]...)
+	}
+	return groups
+}
+
+// Allows reports whether the caller identified by info may use a capability.
+func (p *Policy) Allows(info *mcpauth.TokenInfo, kind proxy.Kind, name string) bool {
+	for _, group := range p.groups(auth.PrincipalOf(info)) {
+		role, ok := p.Roles[group]
+		if ok && role.allows(kind, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// allows reports whether the role grants a capability.
+func (r Role) allows(kind proxy.Kind, name string) bool {
+	var filter proxy.Filter
+	switch kind {
+	case proxy.KindTool:
+		filter = r.Tools
+	case proxy.KindPrompt:
+		filter = r.Prompts
+	case proxy.KindResource:
+		filter = r.Resources
+	}
+
+	// Deny unknown kinds, and kinds the role does not grant
+	return len(filter.Include) > 0 && filter.Allows(name)
+}
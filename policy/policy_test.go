@@ -0,0 +1,104 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/njayp/chimera/auth"
+	"github.com/njayp/chimera/internal/testutil"
+	"github.com/njayp/chimera/proxy"
+)
+
+const testPolicy = `
+roles:
+  readonly:
+    tools:
+      include: ["*.read_*"]
+  ops:
+    tools:
+      include: ["*"]
+    prompts:
+      include: ["*"]
+    resources:
+      include: ["*"]
+      exclude: ["chimera://vault/*"]
+  empty: {}
+members:
+  alice: [ops]
+default: [readonly]
+`
+
+// info builds token info for a principal, as the auth package would.
+func info(t *testing.T, subject string, groups ...string) *mcpauth.TokenInfo {
+	t.Helper()
+
+	verify := auth.APIKeys(map[string]string{"key": subject})
+	info, err := verify(t.Context(), "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth.PrincipalOf(info).Groups = groups
+	return info
+}
+
+func TestPolicyAllows(t *testing.T) {
+	policy, err := Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("Failed to parse policy: %v", err)
+	}
+
+	tests := []struct {
+		info *mcpauth.TokenInfo
+		kind proxy.Kind
+		name string
+		want bool
+	}{
+		{nil, proxy.KindTool, "fs.read_file", true},
+		{nil, proxy.KindTool, "fs.write_file", false},
+		{nil, proxy.KindPrompt, "fs.greet", false},
+		{nil, proxy.KindResource, "chimera://fs/file:///etc", false},
+		{nil, proxy.Kind("sampling"), "fs.read_file", false},
+		{info(t, "bob"), proxy.KindTool, "fs.write_file", false},
+		{info(t, "bob", "ops"), proxy.KindTool, "fs.write_file", true},
+		{info(t, "alice"), proxy.KindResource, "chimera://fs/file:///etc", true},
+		{info(t, "alice"), proxy.KindResource, "chimera://vault/secret", false},
+		{info(t, "bob", "empty"), proxy.KindTool, "fs.write_file", false},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Allows(tt.info, tt.kind, tt.name); got != tt.want {
+			t.Errorf("Allows(%v, %s, %q) = %v, want %v", auth.PrincipalOf(tt.info), tt.kind, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseRejectsUnknownFields(t *testing.T) {
+	if _, err := Parse([]byte(`{"rolez": {}}`)); err == nil {
+		t.Error("Expected unknown field to be rejected")
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte("roles: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewWatcher(t.Context(), path)
+	if err != nil {
+		t.Fatalf("Failed to create watcher: %v", err)
+	}
+	if w.Allows(nil, proxy.KindTool, "fs.read_file") {
+		t.Fatal("Expected empty policy to deny")
+	}
+
+	if err := os.WriteFile(path, []byte(testPolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if !testutil.Eventually(func() bool { return w.Allows(nil, proxy.KindTool, "fs.read_file") }) {
+		t.Error("Expected reloaded policy to allow")
+	}
+}
@@ -0,0 +1,60 @@
+package policy
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/njayp/chimera/internal/filewatch"
+	"github.com/njayp/chimera/proxy"
+)
+
+// Watcher watches a policy file for changes and reloads it.
+// Until a policy loads, every request is denied.
+type Watcher struct {
+	sync.RWMutex
+	path   string
+	policy *Policy
+}
+
+// NewWatcher creates a new Watcher.
+func NewWatcher(ctx context.Context, path string) (*Watcher, error) {
+	w := &Watcher{
+		path: path,
+	}
+	// update after starting to avoid race
+	defer w.update()
+	return w, w.start(ctx)
+}
+
+func (w *Watcher) start(ctx context.Context) error {
+	return filewatch.Watch(ctx, w.path, filewatch.Options{}, w.update)
+}
+
+// Allows consults the current policy.
+func (w *Watcher) Allows(info *mcpauth.TokenInfo, kind proxy.Kind, name string) bool {
+	w.RLock()
+	defer w.RUnlock()
+	return w.policy != nil && w.policy.Allows(info, kind, name)
+}
+
+func (w *Watcher) update() {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		slog.Error("failed to read policy file", "error", err)
+		return
+	}
+
+	// Keep the last good policy on errors
+	policy, err := Parse(data)
+	if err != nil {
+		slog.Error("failed to parse policy file", "error", err)
+		return
+	}
+
+	w.Lock()
+	defer w.Unlock()
+	w.policy = policy
+}
@@ -0,0 +1,57 @@
+package proxy
+
+import (
+	"fmt"
+
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Kind is a type of capability a backend offers.
+type Kind string
+
+// Capability kinds, as passed to an Authorizer.
+const (
+	KindTool     Kind = "tool"
+	KindPrompt   Kind = "prompt"
+	KindResource Kind = "resource"
+)
+
+// Authorizer decides which capabilities each caller may use.
+// It is consulted with exposed names, and resource or template URIs,
+// both when a session is built, to hide capabilities, and on every
+// request, to reject them. info is nil for unauthenticated callers.
+type Authorizer interface {
+	Allows(info *mcpauth.TokenInfo, kind Kind, name string) bool
+}
+
+// AuthorizerFunc adapts a function to the Authorizer interface.
+type AuthorizerFunc func(info *mcpauth.TokenInfo, kind Kind, name string) bool
+
+// Allows calls f(info, kind, name).
+func (f AuthorizerFunc) Allows(info *mcpauth.TokenInfo, kind Kind, name string) bool {
+	return f(info, kind, name)
+}
+
+// allows reports whether the session's caller may see name.
+func (p *proxy) allows(kind Kind, name string) bool {
+	return p.authorizer == nil || p.authorizer.Allows(p.info, kind, name)
+}
+
+// authorize rejects requests the caller may not make.
+// Each HTTP request carries its own token, which is preferred
+// over the one the session was built for.
+func (p *proxy) authorize(extra *mcp.RequestExtra, kind Kind, name string) error {
+	if p.authorizer == nil {
+		return nil
+	}
+
+	info := p.info
+	if extra != nil && extra.TokenInfo != nil {
+		info = extra.TokenInfo
+	}
+	if !p.authorizer.Allows(info, kind, name) {
+		return fmt.Errorf("access to %s %q denied", kind, name)
+	}
+	return nil
+}
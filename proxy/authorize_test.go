@@ -0,0 +1,45 @@
+package proxy
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestAuthorizer(t *testing.T) {
+	ctx := context.Background()
+
+	server := createTestServer("test-server")
+	server.AddTool(&mcp.Tool{Name: "read_data", InputSchema: map[string]any{"type": "object"}}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{}, nil
+	})
+
+	// Only read tools, and nothing once revoked
+	var revoked atomic.Bool
+	m := newManager(&provider{clients: Clients{"backend": &testClient{server: server}}})
+	m.authorizer = AuthorizerFunc(func(_ *mcpauth.TokenInfo, kind Kind, name string) bool {
+		return !revoked.Load() && kind == KindTool && strings.HasPrefix(name, "backend.read_")
+	})
+	session := connectManagerClient(ctx, t, m)
+
+	if names := toolNames(ctx, t, session); len(names) != 1 || names[0] != "backend.read_data" {
+		t.Errorf("Expected only backend.read_data, got %v", names)
+	}
+	if _, err := session.GetPrompt(ctx, &mcp.GetPromptParams{Name: "backend.greet"}); err == nil {
+		t.Error("Expected hidden prompt to be unavailable")
+	}
+
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "backend.read_data"}); err != nil {
+		t.Fatalf("Failed to call allowed tool: %v", err)
+	}
+
+	// Calls are checked again at call time
+	revoked.Store(true)
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "backend.read_data"}); err == nil {
+		t.Error("Expected call to be rejected after revocation")
+	}
+}
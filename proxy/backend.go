@@ -0,0 +1,418 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Supervision settings, variables so tests can shorten them.
+var (
+	// keepAlive is the interval between pings to each backend
+	keepAlive = 30 * time.Second
+	// minBackoff and maxBackoff bound the delay between reconnect attempts
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+// backend owns the session to one MCP server and fans its
+// notifications out to every proxy attached to it.
+// It supervises the session, reconnecting whenever it dies.
+type backend struct {
+	name    string
+	client  Client
+	options Options
+	// prefix and namer build exposed names
+	prefix string
+	namer  Namer
+	// sampling and elicitation rate limit requests to the frontend
+	sampling    *limiter
+	elicitation *limiter
+	// ctx bounds the lifetime of the backend session
+	ctx    context.Context
+	cancel context.CancelFunc
+	// shared backends stay connected while no proxies are attached
+	shared bool
+
+	// connMu serializes connection attempts
+	connMu sync.Mutex
+	// subMu serializes subscription changes
+	subMu sync.Mutex
+	// subscriptions counts subscribed proxies per backend URI
+	subscriptions map[string]int
+
+	// rootsMu guards roots
+	rootsMu sync.Mutex
+	// roots are the frontend roots advertised to the backend
+	roots map[string]*mcp.Root
+
+	mu      sync.Mutex
+	session *mcp.ClientSession
+	// mcpClient answers the session's roots/list requests
+	mcpClient *mcp.Client
+	// logLevel is the level requested from the backend
+	logLevel mcp.LoggingLevel
+	proxies  map[*proxy]*caches
+	// calls counts each proxy's requests in flight
+	calls map[*proxy]int
+	// drained is signalled when a proxy's last call ends
+	drained *sync.Cond
+	// progress maps backend progress tokens to their frontends
+	progress map[string]progressTarget
+	tokens   atomic.Int64
+	// retired backends are closed once the last proxy detaches
+	retired      bool
+	closed       bool
+	reconnecting bool
+}
+
+// caches tracks the names a single proxy registered for a backend.
+type caches struct {
+	tools     *cache
+	prompts   *cache
+	resources *cache
+	templates *cache
+	// subscriptions holds the backend URIs the proxy subscribed to
+	subscriptions map[string]bool
+}
+
+func newCaches() *caches {
+	return &caches{
+		tools:     &cache{names: make(map[string]bool)},
+		prompts:   &cache{names: make(map[string]bool)},
+		resources: &cache{names: make(map[string]bool)},
+		templates: &cache{names: make(map[string]bool)},
+
+		subscriptions: make(map[string]bool),
+	}
+}
+
+func newBackend(ctx context.Context, name string, client Client, shared bool) *backend {
+	ctx, cancel := context.WithCancel(ctx)
+	options := optionsOf(client)
+
+	b := &backend{
+		name:    name,
+		client:  client,
+		options: options,
+		prefix:  Prefix(name, client),
+		namer:   options.namer(),
+
+		sampling:    newLimiter(options.Sampling),
+		elicitation: newLimiter(options.Elicitation),
+		ctx:         ctx,
+		cancel:      cancel,
+		shared:      shared,
+		proxies:     make(map[*proxy]*caches),
+		calls:       make(map[*proxy]int),
+
+		progress: make(map[string]progressTarget),
+
+		subscriptions: make(map[string]int),
+	}
+	b.drained = sync.NewCond(&b.mu)
+	return b
+}
+
+// connect returns the live session, dialing the backend if there is none.
+// On failure the backend keeps retrying in the background.
+func (b *backend) connect() (*mcp.ClientSession, error) {
+	session, err := b.dial()
+	if err != nil {
+		b.reconnect()
+	}
+	return session, err
+}
+
+func (b *backend) dial() (*mcp.ClientSession, error) {
+	b.connMu.Lock()
+	defer b.connMu.Unlock()
+
+	b.mu.Lock()
+	session, closed := b.session, b.closed
+	b.mu.Unlock()
+	if session != nil {
+		return session, nil
+	}
+	if closed {
+		return nil, errors.New("backend is closed")
+	}
+
+	transport := b.client.Transport(b.ctx)
+	if transport == nil {
+		return nil, errors.New("no transport available for client")
+	}
+
+	opts := &mcp.ClientOptions{
+		ToolListChangedHandler: func(ctx context.Context, req *mcp.ToolListChangedRequest) {
+			for p, c := range b.attached() {
+				p.proxyTools(ctx, b, req.Session, c.tools)
+			}
+		},
+		PromptListChangedHandler: func(ctx context.Context, req *mcp.PromptListChangedRequest) {
+			for p, c := range b.attached() {
+				p.proxyPrompts(ctx, b, req.Session, c.prompts)
+			}
+		},
+		ResourceListChangedHandler: func(ctx context.Context, req *mcp.ResourceListChangedRequest) {
+			for p, c := range b.attached() {
+				p.proxyResources(ctx, b, req.Session, c.resources)
+				p.proxyResourceTemplates(ctx, b, req.Session, c.templates)
+			}
+		},
+		ResourceUpdatedHandler:      b.resourceUpdated,
+		ProgressNotificationHandler: b.relayProgress,
+		LoggingMessageHandler:       b.relayLog,
+
+		// Failed pings close the session, which triggers a reconnect
+		KeepAlive: keepAlive,
+	}
+
+	// Setting these handlers advertises the capabilities,
+	// so only do so when the policy allows them
+	if b.options.Sampling.Allow {
+		opts.CreateMessageHandler = b.createMessage
+	}
+	if b.options.Elicitation.Allow {
+		opts.ElicitationHandler = b.elicit
+	}
+
+	c := mcp.NewClient(&mcp.Implementation{
+		Name: "chimera",
+	}, opts)
+
+	b.rootsMu.Lock()
+	c.AddRoots(slices.Collect(maps.Values(b.roots))...)
+	b.rootsMu.Unlock()
+
+	session, err := c.Connect(b.ctx, transport, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	closed = b.closed
+	if !closed {
+		b.session = session
+		b.mcpClient = c
+	}
+	b.mu.Unlock()
+
+	if closed {
+		_ = session.Close()
+		return nil, errors.New("backend is closed")
+	}
+
+	// Restore the level on new sessions
+	b.mu.Lock()
+	level := b.logLevel
+	b.mu.Unlock()
+	if level != "" {
+		b.setLevel(b.ctx, session, level)
+	}
+
+	go b.supervise(session)
+	return session, nil
+}
+
+// supervise waits for a session to die, then withdraws it and reconnects.
+func (b *backend) supervise(session *mcp.ClientSession) {
+	_ = session.Wait()
+
+	b.mu.Lock()
+	if b.session != session {
+		// closed on purpose
+		b.mu.Unlock()
+		return
+	}
+	b.session = nil
+	b.mu.Unlock()
+
+	slog.Warn("lost connection to server", "name", b.name)
+	for p, c := range b.attached() {
+		p.unregister(b, c)
+	}
+	b.reconnect()
+}
+
+// reconnect redials in the background with exponential backoff and jitter,
+// then registers the backend with every attached proxy again.
+func (b *backend) reconnect() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.reconnecting || b.closed {
+		return
+	}
+	b.reconnecting = true
+
+	go func() {
+		for attempt := 0; ; attempt++ {
+			select {
+			case <-b.ctx.Done():
+				return
+			case <-time.After(backoff(attempt)):
+			}
+
+			session, err := b.dial()
+			if err != nil {
+				slog.Warn("failed to reconnect to server", "name", b.name, "attempt", attempt+1, "err", err)
+				continue
+			}
+
+			// Keep going if the session already died, since
+			// supervise defers to the running reconnect
+			b.mu.Lock()
+			alive := b.session == session
+			b.reconnecting = !alive
+			b.mu.Unlock()
+			if !alive {
+				continue
+			}
+
+			slog.Info("reconnected to server", "name", b.name)
+			for p, c := range b.attached() {
+				p.sync(b.ctx, b, session, c)
+			}
+			b.resubscribe(b.ctx, session)
+			return
+		}
+	}()
+}
+
+// backoff returns the delay before a reconnect attempt.
+// The delay doubles each attempt up to maxBackoff,
+// and is jittered so restarted backends are not dialed in lockstep.
+func backoff(attempt int) time.Duration {
+	d := maxBackoff
+	if attempt < 32 {
+		d = min(minBackoff<<attempt, maxBackoff)
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// expose returns the name the proxy exposes for one of the backend's names.
+func (b *backend) expose(name string) string {
+	return b.namer.Name(b.prefix, name)
+}
+
+// attached returns a snapshot of the attached proxies.
+func (b *backend) attached() map[*proxy]*caches {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return maps.Clone(b.proxies)
+}
+
+// attach subscribes a proxy to the backend's notifications.
+func (b *backend) attach(p *proxy) *caches {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := newCaches()
+	b.proxies[p] = c
+	return c
+}
+
+// detach unsubscribes a proxy, closing the backend if nothing else needs it.
+func (b *backend) detach(p *proxy) {
+	b.mu.Lock()
+	c, ok := b.proxies[p]
+	b.mu.Unlock()
+	if !ok {
+		// Already detached, by a config change
+		return
+	}
+	b.releaseAll(c)
+
+	b.mu.Lock()
+	delete(b.proxies, p)
+	idle := len(b.proxies) == 0 && (!b.shared || b.retired)
+	b.mu.Unlock()
+
+	if idle {
+		b.close()
+		return
+	}
+	b.updateRoots()
+}
+
+// retire closes the backend once its last proxy detaches.
+func (b *backend) retire() {
+	b.mu.Lock()
+	b.retired = true
+	idle := len(b.proxies) == 0
+	b.mu.Unlock()
+
+	if idle {
+		b.close()
+	}
+}
+
+// close stops supervision and closes the session.
+func (b *backend) close() {
+	b.mu.Lock()
+	session := b.session
+	b.session = nil
+	b.closed = true
+	b.mu.Unlock()
+
+	defer b.cancel()
+	if session == nil {
+		return
+	}
+	if err := session.Close(); err != nil {
+		slog.Error("failed to close session", "name", b.name, "err", err)
+	}
+}
+
+// pool holds the shared backends, keyed by server name.
+type pool struct {
+	ctx context.Context
+
+	mu       sync.Mutex
+	backends map[string]*backend
+}
+
+func newPool(ctx context.Context) *pool {
+	return &pool{
+		ctx:      ctx,
+		backends: make(map[string]*backend),
+	}
+}
+
+// backend returns the shared backend for name, replacing it if the client changed.
+func (pl *pool) backend(name string, client Client) *backend {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+
+	if b, ok := pl.backends[name]; ok {
+		if b.client == client {
+			return b
+		}
+		b.retire()
+	}
+
+	b := newBackend(pl.ctx, name, client, true)
+	pl.backends[name] = b
+	return b
+}
+
+// prune retires shared backends that are no longer configured.
+func (pl *pool) prune(clients Clients) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+
+	for name, b := range pl.backends {
+		if c, ok := clients[name]; ok && c == b.client {
+			continue
+		}
+		b.retire()
+		delete(pl.backends, name)
+	}
+}
@@ -0,0 +1,182 @@
+package proxy
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/njayp/chimera/internal/testutil"
+)
+
+// countingClient counts how many transports it has handed out.
+type countingClient struct {
+	testClient
+	dials atomic.Int32
+}
+
+func (c *countingClient) Transport(ctx context.Context) mcp.Transport {
+	c.dials.Add(1)
+	return c.testClient.Transport(ctx)
+}
+
+func callEcho(ctx context.Context, t *testing.T, session *mcp.ClientSession, name string) {
+	t.Helper()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      name,
+		Arguments: map[string]any{"message": "hello"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+
+	if text := result.Content[0].(*mcp.TextContent).Text; text != "Echo: hello" {
+		t.Errorf("Expected 'Echo: hello', got %q", text)
+	}
+}
+
+func TestSharedBackend(t *testing.T) {
+	ctx := context.Background()
+	backend := &countingClient{testClient: testClient{server: createTestServer("test-server")}}
+	clients := Clients{"backend": WithOptions(backend, Options{Shared: true})}
+	m := newManager(&provider{clients: clients})
+
+	first := connectManagerClient(ctx, t, m)
+	second := connectManagerClient(ctx, t, m)
+
+	callEcho(ctx, t, first, "backend.echo")
+	callEcho(ctx, t, second, "backend.echo")
+
+	if dials := backend.dials.Load(); dials != 1 {
+		t.Errorf("Expected 1 backend connection, got %d", dials)
+	}
+}
+
+func TestPerSessionBackend(t *testing.T) {
+	ctx := context.Background()
+	backend := &countingClient{testClient: testClient{server: createTestServer("test-server")}}
+	m := newManager(&provider{clients: Clients{"backend": backend}})
+
+	first := connectManagerClient(ctx, t, m)
+	second := connectManagerClient(ctx, t, m)
+
+	callEcho(ctx, t, first, "backend.echo")
+	callEcho(ctx, t, second, "backend.echo")
+
+	if dials := backend.dials.Load(); dials != 2 {
+		t.Errorf("Expected 2 backend connections, got %d", dials)
+	}
+}
+
+func TestSharedBackendReplaced(t *testing.T) {
+	ctx := context.Background()
+	old := &countingClient{testClient: testClient{server: createTestServer("old")}}
+	p := &provider{clients: Clients{"backend": WithOptions(old, Options{Shared: true})}}
+	m := newManager(p)
+	connectManagerClient(ctx, t, m)
+
+	replacement := &countingClient{testClient: testClient{server: createTestServer("new")}}
+	p.clients = Clients{"backend": WithOptions(replacement, Options{Shared: true})}
+	session := connectManagerClient(ctx, t, m)
+
+	result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "chimera://backend/test://data"})
+	if err != nil {
+		t.Fatalf("Failed to read resource: %v", err)
+	}
+
+	if text := result.Contents[0].Text; text != "Data from new" {
+		t.Errorf("Expected 'Data from new', got %q", text)
+	}
+
+	if old.dials.Load() != 1 || replacement.dials.Load() != 1 {
+		t.Errorf("Expected one connection per client, got %d and %d", old.dials.Load(), replacement.dials.Load())
+	}
+}
+
+// killableClient remembers the server side of its latest session.
+type killableClient struct {
+	server *mcp.Server
+
+	mu      sync.Mutex
+	session *mcp.ServerSession
+}
+
+func (c *killableClient) Transport(ctx context.Context) mcp.Transport {
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	session, err := c.server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.session = session
+	return clientTransport
+}
+
+// kill closes the latest session from the server side.
+func (c *killableClient) kill() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session.Close()
+}
+
+func toolNames(ctx context.Context, t *testing.T, session *mcp.ClientSession) []string {
+	t.Helper()
+
+	var names []string
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			t.Fatalf("Failed to list tools: %v", err)
+		}
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+func TestBackendReconnect(t *testing.T) {
+	minBackoff, maxBackoff = 200*time.Millisecond, 400*time.Millisecond
+	t.Cleanup(func() {
+		minBackoff, maxBackoff = time.Second, time.Minute
+	})
+
+	ctx := context.Background()
+	backend := &killableClient{server: createTestServer("test-server")}
+	session := connectProxyClient(ctx, t, Clients{"backend": backend})
+
+	if names := toolNames(ctx, t, session); len(names) != 1 {
+		t.Fatalf("Expected 1 tool, got %v", names)
+	}
+
+	if err := backend.kill(); err != nil {
+		t.Fatalf("Failed to kill backend session: %v", err)
+	}
+
+	// The backend is withdrawn and then restored
+	var sawWithdrawn bool
+	restored := testutil.Eventually(func() bool {
+		names := toolNames(ctx, t, session)
+		if len(names) == 0 {
+			sawWithdrawn = true
+		}
+		return sawWithdrawn && len(names) == 1
+	})
+	if !restored {
+		t.Fatalf("Expected backend to be withdrawn and restored")
+	}
+
+	callEcho(ctx, t, session, "backend.echo")
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := range 40 {
+		d := backoff(attempt)
+		if d < minBackoff/2 || d > maxBackoff {
+			t.Errorf("backoff(%d) = %v, out of range", attempt, d)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// complete forwards a completion request to the backend that owns the
+// referenced prompt or resource template, with the namespace stripped.
+func (p *proxy) complete(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	ref := req.Params.Ref
+	if ref == nil {
+		return nil, fmt.Errorf("missing completion reference")
+	}
+
+	var claims *claims
+	var kind Kind
+	var name string
+	switch ref.Type {
+	case "ref/prompt":
+		claims, kind, name = p.prompts, KindPrompt, ref.Name
+	case "ref/resource":
+		claims, kind, name = p.templates, KindResource, ref.URI
+	default:
+		return nil, fmt.Errorf("unsupported completion reference %q", ref.Type)
+	}
+
+	if err := p.authorize(req.Extra, kind, name); err != nil {
+		return nil, err
+	}
+
+	owner, original, ok := claims.lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown completion reference %q", name)
+	}
+	b, ok := p.backendNamed(owner)
+	if !ok {
+		return nil, fmt.Errorf("server %q is unavailable", owner)
+	}
+
+	b.mu.Lock()
+	session := b.session
+	b.mu.Unlock()
+	if session == nil {
+		return nil, fmt.Errorf("server %q is unavailable", b.name)
+	}
+	if capabilities(session).Completions == nil {
+		// Nothing to offer
+		return &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{Values: []string{}}}, nil
+	}
+
+	meta, done := b.forward(req.Session, req.Params.Meta)
+	defer done()
+
+	forwarded := *ref
+	if ref.Type == "ref/prompt" {
+		forwarded.Name = original
+	} else {
+		forwarded.URI = original
+	}
+	params := &mcp.CompleteParams{
+		Meta:     meta,
+		Argument: req.Params.Argument,
+		Context:  req.Params.Context,
+		Ref:      &forwarded,
+	}
+
+	defer b.track(p)()
+	return session.Complete(ctx, params)
+}
+
+// backendNamed returns the attached backend with the given server name.
+func (p *proxy) backendNamed(name string) (*backend, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, b := range p.backends {
+		if b.name == name {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// interceptInitialize is middleware that only advertises completions
+// when at least one backend does, or when backends may be added later.
+func (p *proxy) interceptInitialize(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		res, err := next(ctx, method, req)
+		if err != nil || method != "initialize" {
+			return res, err
+		}
+
+		if result, ok := res.(*mcp.InitializeResult); ok && !p.live && !p.completes() {
+			result.Capabilities.Completions = nil
+		}
+		return res, err
+	}
+}
+
+// completes reports whether any connected backend offers completions.
+func (p *proxy) completes() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, b := range p.backends {
+		b.mu.Lock()
+		session := b.session
+		b.mu.Unlock()
+
+		if session != nil && capabilities(session).Completions != nil {
+			return true
+		}
+	}
+	return false
+}
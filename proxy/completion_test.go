@@ -0,0 +1,101 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/njayp/chimera/internal/testutil"
+)
+
+func TestCompletion(t *testing.T) {
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "completer", Version: "0.1.0"}, &mcp.ServerOptions{
+		CompletionHandler: func(_ context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+			ref := req.Params.Ref.Name + req.Params.Ref.URI
+			return &mcp.CompleteResult{
+				Completion: mcp.CompletionResultDetails{Values: []string{ref, req.Params.Argument.Value}},
+			}, nil
+		},
+	})
+	server.AddPrompt(&mcp.Prompt{Name: "greet"}, func(context.Context, *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return &mcp.GetPromptResult{}, nil
+	})
+	server.AddResourceTemplate(&mcp.ResourceTemplate{Name: "file", URITemplate: "test://files/{name}"}, func(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return &mcp.ReadResourceResult{}, nil
+	})
+
+	session := connectProxyClient(ctx, t, Clients{"backend": &testClient{server: server}})
+	if session.InitializeResult().Capabilities.Completions == nil {
+		t.Fatal("Expected completions capability")
+	}
+
+	tests := []struct {
+		ref  *mcp.CompleteReference
+		want string
+	}{
+		{&mcp.CompleteReference{Type: "ref/prompt", Name: "backend.greet"}, "greet"},
+		{&mcp.CompleteReference{Type: "ref/resource", URI: "chimera://backend/test://files/{name}"}, "test://files/{name}"},
+	}
+
+	for _, tt := range tests {
+		result, err := session.Complete(ctx, &mcp.CompleteParams{
+			Ref:      tt.ref,
+			Argument: mcp.CompleteParamsArgument{Name: "name", Value: "wor"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to complete %+v: %v", tt.ref, err)
+		}
+
+		// The backend sees the original reference
+		if values := result.Completion.Values; len(values) != 2 || values[0] != tt.want || values[1] != "wor" {
+			t.Errorf("Expected [%q wor], got %v", tt.want, values)
+		}
+	}
+}
+
+func TestCompletionNotAdvertised(t *testing.T) {
+	ctx := context.Background()
+
+	session := connectProxyClient(ctx, t, Clients{"backend": &testClient{server: createTestServer("test-server")}})
+	if session.InitializeResult().Capabilities.Completions != nil {
+		t.Error("Expected no completions capability")
+	}
+}
+
+func TestCompletionLiveReload(t *testing.T) {
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "completer", Version: "0.1.0"}, &mcp.ServerOptions{
+		CompletionHandler: func(context.Context, *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+			return &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{Values: []string{"world"}}}, nil
+		},
+	})
+	server.AddPrompt(&mcp.Prompt{Name: "greet"}, func(context.Context, *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return &mcp.GetPromptResult{}, nil
+	})
+
+	// A completer may be added later, so completions are advertised up front
+	provider := &notifyingProvider{clients: Clients{}}
+	session := connectClient(ctx, t, newManager(provider), nil)
+	if session.InitializeResult().Capabilities.Completions == nil {
+		t.Fatal("Expected completions capability")
+	}
+
+	provider.set(Clients{"backend": &testClient{server: server}})
+	params := &mcp.CompleteParams{
+		Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "backend.greet"},
+		Argument: mcp.CompleteParamsArgument{Name: "name", Value: "wor"},
+	}
+	var values []string
+	if !testutil.Eventually(func() bool {
+		result, err := session.Complete(ctx, params)
+		if err == nil {
+			values = result.Completion.Values
+		}
+		return len(values) == 1 && values[0] == "world"
+	}) {
+		t.Errorf("Expected [world] once the completer is added, got %v", values)
+	}
+}
@@ -1,3 +1,4 @@
 // Package proxy aggregates multiple MCP servers into one HTTP endpoint.
-// Automatically prefixes tool/resource/prompt names to prevent conflicts.
+// Automatically prefixes tool/resource/prompt names to prevent conflicts,
+// using a configurable Namer.
 package proxy
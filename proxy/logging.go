@@ -0,0 +1,144 @@
+package proxy
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// levels orders MCP logging levels from most to least verbose.
+var levels = []mcp.LoggingLevel{"debug", "info", "notice", "warning", "error", "critical", "alert", "emergency"}
+
+// ValidLevel reports whether level is an MCP logging level.
+func ValidLevel(level mcp.LoggingLevel) bool {
+	return slices.Contains(levels, level)
+}
+
+// moreVerbose returns the more verbose of two levels, ignoring unset ones.
+func moreVerbose(a, b mcp.LoggingLevel) mcp.LoggingLevel {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	case slices.Index(levels, a) <= slices.Index(levels, b):
+		return a
+	default:
+		return b
+	}
+}
+
+// slogLevel maps an MCP logging level to the nearest slog level.
+func slogLevel(level mcp.LoggingLevel) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "info", "notice":
+		return slog.LevelInfo
+	case "warning":
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// interceptSetLevel is middleware that fans a frontend's logging/setLevel out to its backends.
+func (p *proxy) interceptSetLevel(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		res, err := next(ctx, method, req)
+		if err != nil || method != "logging/setLevel" {
+			return res, err
+		}
+
+		params, ok := req.GetParams().(*mcp.SetLoggingLevelParams)
+		if !ok {
+			return res, err
+		}
+
+		p.mu.Lock()
+		p.logLevel = params.Level
+		backends := slices.Collect(maps.Values(p.backends))
+		p.mu.Unlock()
+
+		for _, b := range backends {
+			b.updateLevel(ctx)
+		}
+		return res, err
+	}
+}
+
+// frontendLevel returns the level the frontend asked for, if any.
+func (p *proxy) frontendLevel() mcp.LoggingLevel {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.logLevel
+}
+
+// relaysLogs reports whether the backend's log messages reach the frontends.
+func (b *backend) relaysLogs() bool {
+	return !b.shared || b.options.LogBroadcast
+}
+
+// updateLevel sets the backend's level to the most verbose one any attached
+// frontend it relays logs to, or the backend's options, asks for.
+func (b *backend) updateLevel(ctx context.Context) {
+	level := b.options.LogLevel
+	if b.relaysLogs() {
+		for p := range b.attached() {
+			level = moreVerbose(level, p.frontendLevel())
+		}
+	}
+
+	b.mu.Lock()
+	session := b.session
+	changed := level != "" && level != b.logLevel
+	if changed {
+		b.logLevel = level
+	}
+	b.mu.Unlock()
+
+	if changed && session != nil {
+		b.setLevel(ctx, session, level)
+	}
+}
+
+// setLevel asks a backend session to log at level.
+func (b *backend) setLevel(ctx context.Context, session *mcp.ClientSession, level mcp.LoggingLevel) {
+	if capabilities(session).Logging == nil {
+		return
+	}
+	if err := session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: level}); err != nil {
+		slog.Error("failed to set log level", "name", b.name, "level", level, "err", err)
+	}
+}
+
+// relayLog forwards a backend log message to the frontends, tagged with the
+// backend, and optionally to chimera's own log. Each frontend session drops
+// messages below the level it asked for. Messages cannot be traced to the
+// call that caused them, so shared backends only relay with LogBroadcast.
+func (b *backend) relayLog(ctx context.Context, req *mcp.LoggingMessageRequest) {
+	params := *req.Params
+	params.Logger = b.prefix
+	if req.Params.Logger != "" {
+		params.Logger += "." + req.Params.Logger
+	}
+
+	if b.relaysLogs() {
+		for p := range b.attached() {
+			session := p.frontend()
+			if session == nil {
+				continue
+			}
+			if err := session.Log(ctx, &params); err != nil {
+				slog.Error("failed to relay log message", "name", b.name, "err", err)
+			}
+		}
+	}
+
+	if b.options.LogLocal {
+		slog.Log(ctx, slogLevel(params.Level), "backend log message", "name", b.name, "logger", params.Logger, "data", params.Data)
+	}
+}
@@ -0,0 +1,144 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// createLoggingServer returns a server with a "log" tool that logs one
+// message at each of debug and error.
+func createLoggingServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "logger", Version: "0.1.0"}, nil)
+	mcp.AddTool(
+		server,
+		&mcp.Tool{Name: "log"},
+		func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, struct{}, error) {
+			for _, level := range []mcp.LoggingLevel{"debug", "error"} {
+				err := req.Session.Log(ctx, &mcp.LoggingMessageParams{
+					Level:  level,
+					Logger: "worker",
+					Data:   string(level),
+				})
+				if err != nil {
+					return nil, struct{}{}, err
+				}
+			}
+			return &mcp.CallToolResult{}, struct{}{}, nil
+		},
+	)
+	return server
+}
+
+func TestLogForwarding(t *testing.T) {
+	ctx := context.Background()
+
+	logs := make(chan *mcp.LoggingMessageParams, 2)
+	opts := &mcp.ClientOptions{
+		LoggingMessageHandler: func(_ context.Context, req *mcp.LoggingMessageRequest) {
+			logs <- req.Params
+		},
+	}
+
+	clients := Clients{"backend": &testClient{server: createLoggingServer()}}
+	session := connectClient(ctx, t, newManager(&provider{clients: clients}), opts)
+
+	if err := session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "debug"}); err != nil {
+		t.Fatalf("Failed to set log level: %v", err)
+	}
+
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "backend.log"}); err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+
+	// Both levels arrive, so setLevel reached the backend
+	for _, level := range []mcp.LoggingLevel{"debug", "error"} {
+		select {
+		case l := <-logs:
+			if l.Level != level || l.Logger != "backend.worker" {
+				t.Errorf("Unexpected log message %+v", l)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for %s log message", level)
+		}
+	}
+}
+
+func TestMoreVerbose(t *testing.T) {
+	tests := []struct {
+		a, b, want mcp.LoggingLevel
+	}{
+		{"", "", ""},
+		{"", "error", "error"},
+		{"info", "", "info"},
+		{"debug", "error", "debug"},
+		{"warning", "notice", "notice"},
+	}
+
+	for _, tt := range tests {
+		if got := moreVerbose(tt.a, tt.b); got != tt.want {
+			t.Errorf("moreVerbose(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSharedLogs(t *testing.T) {
+	tests := []struct {
+		name      string
+		broadcast bool
+		// level is what the backend is asked to log at
+		level mcp.LoggingLevel
+	}{
+		{name: "private"},
+		{name: "broadcast", broadcast: true, level: "debug"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			options := Options{Shared: true, LogBroadcast: tt.broadcast}
+			clients := Clients{"backend": WithOptions(&testClient{server: createLoggingServer()}, options)}
+			m := newManager(&provider{clients: clients})
+
+			logs := make(chan *mcp.LoggingMessageParams, 2)
+			caller := connectClient(ctx, t, m, nil)
+			other := connectClient(ctx, t, m, &mcp.ClientOptions{
+				LoggingMessageHandler: func(_ context.Context, req *mcp.LoggingMessageRequest) {
+					logs <- req.Params
+				},
+			})
+
+			if err := caller.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "debug"}); err != nil {
+				t.Fatalf("Failed to set log level: %v", err)
+			}
+			if err := other.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "error"}); err != nil {
+				t.Fatalf("Failed to set log level: %v", err)
+			}
+
+			// Without broadcast, clients cannot turn on the backend's logs
+			b := m.pool.backend("backend", clients["backend"])
+			b.mu.Lock()
+			level := b.logLevel
+			b.mu.Unlock()
+			if level != tt.level {
+				t.Fatalf("Expected backend level %q, got %q", tt.level, level)
+			}
+			if !tt.broadcast {
+				return
+			}
+
+			if _, err := caller.CallTool(ctx, &mcp.CallToolParams{Name: "backend.log"}); err != nil {
+				t.Fatalf("Failed to call tool: %v", err)
+			}
+			select {
+			case l := <-logs:
+				if l.Level != "error" {
+					t.Errorf("Unexpected log message %+v", l)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Timed out waiting for the broadcast log message")
+			}
+		})
+	}
+}
@@ -2,9 +2,11 @@ package proxy
 
 import (
 	"context"
+	"log/slog"
 	"net/http"
 	"sync"
 
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -16,42 +18,155 @@ type Provider interface {
 	Clients() Clients
 }
 
+// HandlerOptions configures HandlerWithOptions.
+type HandlerOptions struct {
+	// Authorizer, if set, limits what each caller may see and use.
+	// The caller is identified by the token info that auth middleware
+	// placed in the request context.
+	Authorizer Authorizer
+}
+
 // manager wraps multiple MCP servers and exposes them as one.
 type manager struct {
-	provider Provider
+	provider   Provider
+	authorizer Authorizer
+	// pool holds backends shared across sessions
+	pool *pool
+	// live is set when the provider notifies of changes
+	live bool
+
+	mu sync.Mutex
+	// sessions maps live proxies to the contexts that scope them
+	sessions map[*proxy]context.Context
+}
+
+func newManager(provider Provider) *manager {
+	m := &manager{
+		provider: provider,
+		pool:     newPool(context.Background()),
+		sessions: make(map[*proxy]context.Context),
+	}
+
+	if n, ok := provider.(Notifier); ok {
+		m.live = true
+		n.Subscribe(context.Background(), m.reload)
+	}
+	return m
 }
 
 // Handler returns an HTTP handler that aggregates all clients into one MCP server.
 // Each HTTP request creates a new aggregated server instance with prefixed names.
+// Sessions may select a subset of the servers; see RequestedServers.
 func Handler(provider Provider) *mcp.StreamableHTTPHandler {
-	m := &manager{provider: provider}
+	return HandlerWithOptions(provider, nil)
+}
+
+// HandlerWithOptions is like Handler, configured by opts.
+func HandlerWithOptions(provider Provider, opts *HandlerOptions) *mcp.StreamableHTTPHandler {
+	m := newManager(provider)
+	if opts != nil {
+		m.authorizer = opts.Authorizer
+	}
 
 	// Create HTTP handler that creates a new aggregating server per session
 	// This allows different tools to be available for different sessions
 	return mcp.NewStreamableHTTPHandler(func(req *http.Request) *mcp.Server {
-		return m.newProxy(req.Context())
+		clients, err := m.selector(req)
+		if err != nil {
+			// The handler rejects the request
+			slog.Warn("rejected session", "err", err)
+			return nil
+		}
+		return m.newProxy(withRequestHeader(req.Context(), req.Header.Clone()), clients)
 	}, nil)
 }
 
 // each newProxy creates a new MCP server instance that aggregates
-// all configured backend servers.
-func (m *manager) newProxy(ctx context.Context) *mcp.Server {
-	p := &proxy{}
+// the backend servers that clients provides.
+func (m *manager) newProxy(ctx context.Context, clients func() Clients) *mcp.Server {
+	// The request that creates the proxy ends long before the session does,
+	// so backends live until the frontend session closes instead.
+	// Sessions the request leaves uninitialized are already closed.
+	reqCtx := ctx
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	var once sync.Once
+	context.AfterFunc(reqCtx, func() {
+		once.Do(cancel)
+	})
+	watch := func(session *mcp.ServerSession) {
+		once.Do(func() {
+			go func() {
+				_ = session.Wait()
+				cancel()
+			}()
+		})
+	}
+
+	p := newProxy()
+	p.clients, p.live = clients, m.live
+	p.authorizer, p.info = m.authorizer, mcpauth.TokenInfoFromContext(ctx)
 	p.server = mcp.NewServer(&mcp.Implementation{
 		Name: "chimera",
 	}, &mcp.ServerOptions{
-		// TODO
-		RootsListChangedHandler: nil,
+		InitializedHandler: func(_ context.Context, req *mcp.InitializedRequest) {
+			p.mu.Lock()
+			p.session = req.Session
+			p.mu.Unlock()
+
+			p.rootsChanged(req.Session)
+		},
+		RootsListChangedHandler: func(_ context.Context, req *mcp.RootsListChangedRequest) {
+			p.rootsChanged(req.Session)
+		},
+		SubscribeHandler:   p.subscribe,
+		UnsubscribeHandler: p.unsubscribe,
+		CompletionHandler:  p.complete,
+
+		// Backends may be added later, so advertise everything up front
+		HasTools:     m.live,
+		HasPrompts:   m.live,
+		HasResources: m.live,
 	})
+	p.server.AddReceivingMiddleware(p.interceptSetLevel, p.interceptInitialize, p.interceptRoots, onInitialize(watch))
+
+	// Hold off reloads until the initial backends are attached
+	p.reloadMu.Lock()
+	defer p.reloadMu.Unlock()
+	m.track(ctx, p)
+
+	// Prune with every client, since this session may use only some
+	m.pool.prune(m.provider.Clients())
 
 	// Connect to all backend servers async
 	wg := sync.WaitGroup{}
-	for n, c := range m.provider.Clients() {
+	for n, c := range clients() {
 		wg.Go(func() {
-			p.proxyServer(ctx, n, c)
+			p.proxyServer(ctx, m.backend(ctx, n, c))
 		})
 	}
 	wg.Wait()
 
 	return p.server
 }
+
+// onInitialize is middleware that calls f with each session that
+// initializes, whether or not initialization succeeds.
+func onInitialize(f func(*mcp.ServerSession)) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if session, ok := req.GetSession().(*mcp.ServerSession); ok && method == "initialize" {
+				f(session)
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// backend returns the pooled backend for shared clients,
+// or a fresh one scoped to ctx otherwise.
+func (m *manager) backend(ctx context.Context, name string, client Client) *backend {
+	if optionsOf(client).Shared {
+		return m.pool.backend(name, client)
+	}
+	return newBackend(ctx, name, client, false)
+}
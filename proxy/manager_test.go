@@ -0,0 +1,74 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/njayp/chimera/internal/testutil"
+)
+
+// sessions counts the sessions connected to a test client's server.
+func (c *testClient) sessions() int {
+	n := 0
+	for range c.server.Sessions() {
+		n++
+	}
+	return n
+}
+
+func TestUninitializedSession(t *testing.T) {
+	client := &testClient{server: createTestServer("backend")}
+	server := httptest.NewServer(Handler(&provider{clients: Clients{"backend": client}}))
+	t.Cleanup(server.Close)
+
+	post := func(body string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+		return res
+	}
+
+	// The handler closes sessions that do not start with initialize
+	post(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	if !testutil.Eventually(func() bool { return client.sessions() == 0 }) {
+		t.Fatalf("Expected the backend to be closed with the rejected session, got %d sessions", client.sessions())
+	}
+
+	// Initialize, but never send notifications/initialized
+	res := post(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test"}}}`)
+	id := res.Header.Get("Mcp-Session-Id")
+	if id == "" {
+		t.Fatalf("Expected a session ID, got status %d", res.StatusCode)
+	}
+	if n := client.sessions(); n != 1 {
+		t.Fatalf("Expected the backend to be connected, got %d sessions", n)
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Mcp-Session-Id", id)
+	del, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = del.Body.Close()
+
+	if !testutil.Eventually(func() bool { return client.sessions() == 0 }) {
+		t.Errorf("Expected the backend to be closed with the session, got %d sessions", client.sessions())
+	}
+}
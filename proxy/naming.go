@@ -0,0 +1,133 @@
+package proxy
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Namer maps a backend's capability names to the names the proxy exposes.
+// prefix is the backend's alias, or its server name if it has none.
+type Namer interface {
+	Name(prefix, name string) string
+}
+
+// NamerFunc adapts a function to the Namer interface.
+type NamerFunc func(prefix, name string) string
+
+// Name calls f(prefix, name).
+func (f NamerFunc) Name(prefix, name string) string {
+	return f(prefix, name)
+}
+
+// Separator returns a Namer that joins the prefix and name with sep.
+// Names that already carry the prefix are left alone, so a backend's
+// "foo" and "prefix.foo" collide, which claims reports.
+func Separator(sep string) Namer {
+	return NamerFunc(func(prefix, name string) string {
+		if strings.HasPrefix(name, prefix+sep) {
+			return name
+		}
+		return prefix + sep + name
+	})
+}
+
+// Built-in naming strategies.
+var (
+	// Dot exposes names like "backend.tool".
+	Dot = Separator(".")
+	// DoubleUnderscore exposes names like "backend__tool",
+	// for hosts that reject dots in tool names.
+	DoubleUnderscore = Separator("__")
+	// Bare exposes names unchanged.
+	// Names claimed by more than one backend are reported and only the first is exposed.
+	Bare Namer = NamerFunc(func(_, name string) string {
+		return name
+	})
+)
+
+// Naming selects a built-in naming strategy in config files.
+type Naming string
+
+// Supported naming strategies.
+const (
+	NamingDot        Naming = "dot"
+	NamingUnderscore Naming = "underscore"
+	NamingNone       Naming = "none"
+)
+
+// UnmarshalText rejects unknown strategies.
+func (n *Naming) UnmarshalText(text []byte) error {
+	switch naming := Naming(text); naming {
+	case "", NamingDot, NamingUnderscore, NamingNone:
+		*n = naming
+		return nil
+	default:
+		return fmt.Errorf("unknown naming strategy %q", naming)
+	}
+}
+
+// Namer returns the strategy's Namer, defaulting to Dot.
+func (n Naming) Namer() Namer {
+	switch n {
+	case NamingUnderscore:
+		return DoubleUnderscore
+	case NamingNone:
+		return Bare
+	default:
+		return Dot
+	}
+}
+
+// claims tracks which backend owns each exposed name, to detect collisions
+// and to route requests that only carry the exposed name.
+type claims struct {
+	sync.Mutex
+	owners map[string]claim
+}
+
+// claim records the backend that owns an exposed name, and the name
+// the backend knows it by.
+type claim struct {
+	backend  string
+	original string
+}
+
+func newClaims() *claims {
+	return &claims{owners: make(map[string]claim)}
+}
+
+// claim reserves name for backend, returning the current owner and
+// whether backend now owns it. A backend whose own names collide keeps
+// the first one.
+func (c *claims) claim(backend, name, original string) (string, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	if owner, ok := c.owners[name]; ok && owner != (claim{backend: backend, original: original}) {
+		return owner.backend, false
+	}
+	c.owners[name] = claim{backend: backend, original: original}
+	return backend, true
+}
+
+// lookup returns the backend that owns name, and its original name.
+func (c *claims) lookup(name string) (string, string, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	owner, ok := c.owners[name]
+	return owner.backend, owner.original, ok
+}
+
+// release frees names owned by backend.
+func (c *claims) release(backend string, names ...string) {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, name := range names {
+		if c.owners[name].backend == backend {
+			delete(c.owners, name)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestSeparator(t *testing.T) {
+	tests := []struct {
+		namer Namer
+		name  string
+		want  string
+	}{
+		{Dot, "echo", "backend.echo"},
+		{Dot, "backend.echo", "backend.echo"},
+		{DoubleUnderscore, "echo", "backend__echo"},
+		{Separator("-"), "echo", "backend-echo"},
+		{Bare, "echo", "echo"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.namer.Name("backend", tt.name); got != tt.want {
+			t.Errorf("Name(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNamingUnmarshal(t *testing.T) {
+	var options Options
+	if err := json.Unmarshal([]byte(`{"naming": "underscore"}`), &options); err != nil {
+		t.Fatalf("Failed to parse naming: %v", err)
+	}
+
+	if got := options.namer().Name("backend", "echo"); got != "backend__echo" {
+		t.Errorf("Expected 'backend__echo', got %q", got)
+	}
+
+	if err := json.Unmarshal([]byte(`{"naming": "slash"}`), &options); err == nil {
+		t.Error("Expected error for unknown naming strategy")
+	}
+}
+
+func TestProxyAlias(t *testing.T) {
+	ctx := context.Background()
+	options := Options{Alias: "fs", Naming: NamingUnderscore}
+	clients := Clients{"filesystem": WithOptions(&testClient{server: createTestServer("test-server")}, options)}
+	session := connectProxyClient(ctx, t, clients)
+
+	if names := toolNames(ctx, t, session); len(names) != 1 || names[0] != "fs__echo" {
+		t.Errorf("Expected tool 'fs__echo', got %v", names)
+	}
+
+	callEcho(ctx, t, session, "fs__echo")
+}
+
+func TestProxyBareCollision(t *testing.T) {
+	ctx := context.Background()
+	options := Options{Naming: NamingNone}
+	clients := Clients{
+		"backend1": WithOptions(&testClient{server: createTestServer("server1")}, options),
+		"backend2": WithOptions(&testClient{server: createTestServer("server2")}, options),
+	}
+	session := connectProxyClient(ctx, t, clients)
+
+	// Only one backend wins the colliding name
+	names := toolNames(ctx, t, session)
+	if !slices.Equal(names, []string{"echo"}) {
+		t.Errorf("Expected single tool 'echo', got %v", names)
+	}
+
+	callEcho(ctx, t, session, "echo")
+}
+
+func TestProxyPrefixCollision(t *testing.T) {
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "github", Version: "0.1.0"}, nil)
+	for _, name := range []string{"foo", "github.foo"} {
+		server.AddTool(&mcp.Tool{Name: name, InputSchema: &jsonschema.Schema{Type: "object"}}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: name}}}, nil
+		})
+	}
+	session := connectProxyClient(ctx, t, Clients{"github": &testClient{server: server}})
+
+	// "foo" is exposed as github.foo first, so the backend's own
+	// github.foo collides instead of replacing it
+	names := toolNames(ctx, t, session)
+	if !slices.Equal(names, []string{"github.foo"}) {
+		t.Errorf("Expected single tool 'github.foo', got %v", names)
+	}
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "github.foo"})
+	if err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+	if got := res.Content[0].(*mcp.TextContent).Text; got != "foo" {
+		t.Errorf("Expected the first tool to keep the name, got %q", got)
+	}
+}
+
+func TestClaimsSameBackend(t *testing.T) {
+	c := newClaims()
+	if _, ok := c.claim("github", "foo", "foo"); !ok {
+		t.Fatal("Expected the first name to be claimed")
+	}
+	if _, ok := c.claim("github", "foo", "foo"); !ok {
+		t.Error("Expected the owner to claim its name again")
+	}
+	if owner, ok := c.claim("github", "foo", "FOO"); ok || owner != "github" {
+		t.Errorf("Expected a collision with github, got owner %q, ok %v", owner, ok)
+	}
+	if _, original, _ := c.lookup("foo"); original != "foo" {
+		t.Errorf("Expected the first name to keep the claim, got %q", original)
+	}
+}
@@ -0,0 +1,143 @@
+package proxy
+
+import (
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Options configures how a single backend is proxied.
+type Options struct {
+	// Shared multiplexes one backend session across all frontend sessions.
+	// Leave unset for stateful servers that need a session per client.
+	Shared bool `json:"shared,omitempty"`
+
+	// Tools, Prompts and Resources select which capabilities are exposed.
+	// Tools and prompts are matched by name, resources by URI,
+	// before any prefix is applied.
+	Tools     Filter `json:"tools,omitzero"`
+	Prompts   Filter `json:"prompts,omitzero"`
+	Resources Filter `json:"resources,omitzero"`
+	// Overlays rewrite tools, keyed by their name before any prefix is applied.
+	Overlays map[string]ToolOverlay `json:"overlays,omitempty"`
+	// Roots selects which frontend roots, by URI, the backend may see.
+	Roots Filter `json:"roots,omitzero"`
+
+	// Sampling and Elicitation govern the backend's requests to the frontend client.
+	Sampling    Policy `json:"sampling,omitzero"`
+	Elicitation Policy `json:"elicitation,omitzero"`
+
+	// LogLevel is requested from the backend even when no client asks for logs.
+	// Clients may still ask for more verbose levels.
+	LogLevel mcp.LoggingLevel `json:"logLevel,omitempty"`
+	// LogLocal also writes the backend's log messages to chimera's own log.
+	LogLocal bool `json:"logLocal,omitempty"`
+	// LogBroadcast relays a shared backend's log messages to every attached
+	// client. They are otherwise kept from clients, since a shared backend's
+	// messages may reveal what other callers are doing.
+	LogBroadcast bool `json:"logBroadcast,omitempty"`
+
+	// Alias replaces the server name when prefixing names.
+	Alias string `json:"alias,omitempty"`
+	// Naming selects a built-in naming strategy, defaulting to NamingDot.
+	Naming Naming `json:"naming,omitempty"`
+	// Separator joins the prefix and names, overriding Naming.
+	Separator string `json:"separator,omitempty"`
+	// Namer overrides both Naming and Separator.
+	Namer Namer `json:"-"`
+}
+
+// namer resolves the naming strategy.
+func (o Options) namer() Namer {
+	switch {
+	case o.Namer != nil:
+		return o.Namer
+	case o.Separator != "":
+		return Separator(o.Separator)
+	default:
+		return o.Naming.Namer()
+	}
+}
+
+// Filter selects names with glob patterns, where * matches any run of
+// characters and ? matches any single character.
+// A name is allowed if it matches an include pattern (or there are none),
+// and matches no exclude pattern.
+type Filter struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Allows reports whether the filter admits name.
+func (f Filter) Allows(name string) bool {
+	if len(f.Include) > 0 && !slices.ContainsFunc(f.Include, func(pattern string) bool {
+		return glob(pattern, name)
+	}) {
+		return false
+	}
+
+	return !slices.ContainsFunc(f.Exclude, func(pattern string) bool {
+		return glob(pattern, name)
+	})
+}
+
+// glob reports whether name matches pattern.
+func glob(pattern, name string) bool {
+	// Position to resume from after the last *
+	star, next := -1, 0
+
+	p, n := 0, 0
+	for n < len(name) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, next = p, n
+			p++
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == name[n]):
+			p++
+			n++
+		case star >= 0:
+			// Let the last * swallow one more character
+			next++
+			p, n = star+1, next
+		default:
+			return false
+		}
+	}
+
+	// Trailing stars match the empty string
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// configured pairs a Client with its Options.
+type configured struct {
+	Client
+	options Options
+}
+
+// WithOptions attaches proxy options to a client.
+func WithOptions(client Client, options Options) Client {
+	return &configured{
+		Client:  client,
+		options: options,
+	}
+}
+
+// optionsOf returns the options attached to a client, if any.
+func optionsOf(client Client) Options {
+	if c, ok := client.(*configured); ok {
+		return c.options
+	}
+	return Options{}
+}
+
+// Prefix returns the prefix of the names the server called name exposes:
+// its alias, if it has one, or else its name.
+func Prefix(name string, client Client) string {
+	if alias := optionsOf(client).Alias; alias != "" {
+		return alias
+	}
+	return name
+}
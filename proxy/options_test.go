@@ -0,0 +1,47 @@
+package proxy
+
+import "testing"
+
+func TestFilterAllows(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		allows map[string]bool
+	}{
+		{
+			name:   "empty",
+			filter: Filter{},
+			allows: map[string]bool{"read_file": true, "write_file": true},
+		},
+		{
+			name:   "exclude",
+			filter: Filter{Exclude: []string{"write_*"}},
+			allows: map[string]bool{"read_file": true, "write_file": false},
+		},
+		{
+			name:   "include",
+			filter: Filter{Include: []string{"read_*", "list_?"}},
+			allows: map[string]bool{"read_file": true, "list_a": true, "list_ab": false, "write_file": false},
+		},
+		{
+			name:   "exclude wins",
+			filter: Filter{Include: []string{"*_file"}, Exclude: []string{"write*"}},
+			allows: map[string]bool{"read_file": true, "write_file": false},
+		},
+		{
+			name:   "uri",
+			filter: Filter{Include: []string{"file:///logs/*"}},
+			allows: map[string]bool{"file:///logs/app/today.log": true, "file:///etc/passwd": false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, want := range tt.allows {
+				if got := tt.filter.Allows(name); got != want {
+					t.Errorf("Allows(%q) = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolOverlay rewrites how a backend tool is presented to clients.
+// Unset fields leave the backend's values alone.
+type ToolOverlay struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Annotations replaces the backend's annotations wholesale.
+	Annotations *mcp.ToolAnnotations `json:"annotations,omitempty"`
+	// Meta is merged over the backend's _meta, key by key.
+	Meta mcp.Meta `json:"_meta,omitempty"`
+	// ValidateOutput checks structuredContent against the tool's
+	// outputSchema before returning results to the client.
+	ValidateOutput bool `json:"validateOutput,omitempty"`
+}
+
+// apply rewrites tool in place.
+func (o ToolOverlay) apply(tool *mcp.Tool) {
+	if o.Title != "" {
+		tool.Title = o.Title
+	}
+	if o.Description != "" {
+		tool.Description = o.Description
+	}
+	if o.Annotations != nil {
+		annotations := *o.Annotations
+		tool.Annotations = &annotations
+	}
+	if len(o.Meta) > 0 {
+		meta := maps.Clone(tool.Meta)
+		if meta == nil {
+			meta = make(mcp.Meta, len(o.Meta))
+		}
+		maps.Copy(meta, o.Meta)
+		tool.Meta = meta
+	}
+}
+
+// outputValidator checks tool results against an output schema.
+type outputValidator struct {
+	resolved *jsonschema.Resolved
+}
+
+// newOutputValidator resolves a tool's output schema.
+// It returns nil if the tool has no output schema.
+func newOutputValidator(tool *mcp.Tool) (*outputValidator, error) {
+	if tool.OutputSchema == nil {
+		return nil, nil
+	}
+
+	// The schema arrives as arbitrary JSON, so round trip it
+	data, err := json.Marshal(tool.OutputSchema)
+	if err != nil {
+		return nil, err
+	}
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &outputValidator{resolved: resolved}, nil
+}
+
+// validate reports whether res carries structured content matching the schema.
+// Error results are passed through unchecked.
+func (v *outputValidator) validate(res *mcp.CallToolResult) error {
+	if res == nil || res.IsError {
+		return nil
+	}
+	if res.StructuredContent == nil {
+		return fmt.Errorf("missing structured content")
+	}
+
+	data, err := json.Marshal(res.StructuredContent)
+	if err != nil {
+		return err
+	}
+	var content any
+	if err := json.Unmarshal(data, &content); err != nil {
+		return err
+	}
+	return v.resolved.Validate(content)
+}
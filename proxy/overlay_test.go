@@ -0,0 +1,88 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// createStructuredServer returns a server with a "count" tool whose output
+// schema requires an integer, but which returns whatever it is asked to.
+func createStructuredServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "structured", Version: "0.1.0"}, nil)
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "count",
+			Description: "Count things",
+			InputSchema: map[string]any{"type": "object"},
+			OutputSchema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"count": map[string]any{"type": "integer"}},
+				"required":   []string{"count"},
+			},
+			Meta: mcp.Meta{"origin": "backend"},
+		},
+		func(_ context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{StructuredContent: req.Params.Arguments}, nil
+		},
+	)
+	return server
+}
+
+func TestToolOverlay(t *testing.T) {
+	ctx := context.Background()
+
+	destructive := true
+	options := Options{Overlays: map[string]ToolOverlay{
+		"count": {
+			Title:       "Counter",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructive},
+			Meta:        mcp.Meta{"team": "ops"},
+		},
+	}}
+	clients := Clients{"backend": WithOptions(&testClient{server: createStructuredServer()}, options)}
+	session := connectProxyClient(ctx, t, clients)
+
+	var tool *mcp.Tool
+	for tl, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			t.Fatalf("Failed to list tools: %v", err)
+		}
+		tool = tl
+	}
+
+	if tool.Title != "Counter" || tool.Description != "Count things" {
+		t.Errorf("Expected overlaid title and original description, got %q and %q", tool.Title, tool.Description)
+	}
+	if tool.Annotations == nil || tool.Annotations.DestructiveHint == nil || !*tool.Annotations.DestructiveHint {
+		t.Errorf("Expected destructive hint, got %+v", tool.Annotations)
+	}
+	if tool.Meta["origin"] != "backend" || tool.Meta["team"] != "ops" {
+		t.Errorf("Expected merged _meta, got %v", tool.Meta)
+	}
+}
+
+func TestValidateOutput(t *testing.T) {
+	ctx := context.Background()
+
+	options := Options{Overlays: map[string]ToolOverlay{"count": {ValidateOutput: true}}}
+	clients := Clients{"backend": WithOptions(&testClient{server: createStructuredServer()}, options)}
+	session := connectProxyClient(ctx, t, clients)
+
+	tests := []struct {
+		args  map[string]any
+		valid bool
+	}{
+		{map[string]any{"count": 3}, true},
+		{map[string]any{"count": "three"}, false},
+		{map[string]any{}, false},
+	}
+
+	for _, tt := range tests {
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "backend.count", Arguments: tt.args})
+		if valid := err == nil; valid != tt.valid {
+			t.Errorf("CallTool(%v): expected valid %v, got err %v", tt.args, tt.valid, err)
+		}
+	}
+}
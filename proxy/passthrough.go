@@ -0,0 +1,138 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Policy governs server-initiated requests, like sampling, from a backend.
+type Policy struct {
+	// Allow forwards requests to the frontend client. They are denied by default.
+	Allow bool `json:"allow,omitempty"`
+	// PerMinute caps forwarded requests per minute. Zero means no cap.
+	PerMinute int `json:"perMinute,omitempty"`
+}
+
+// limiter enforces a Policy's rate limit over a sliding minute.
+type limiter struct {
+	sync.Mutex
+	perMinute int
+	recent    []time.Time
+}
+
+func newLimiter(policy Policy) *limiter {
+	return &limiter{perMinute: policy.PerMinute}
+}
+
+// allow reports whether another request fits in the limit, and records it if so.
+func (l *limiter) allow() bool {
+	if l.perMinute <= 0 {
+		return true
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	now := time.Now()
+	for len(l.recent) > 0 && now.Sub(l.recent[0]) >= time.Minute {
+		l.recent = l.recent[1:]
+	}
+	if len(l.recent) >= l.perMinute {
+		return false
+	}
+	l.recent = append(l.recent, now)
+	return true
+}
+
+// track records a call in flight from p, and returns a func that ends it.
+func (b *backend) track(p *proxy) func() {
+	b.mu.Lock()
+	b.calls[p]++
+	b.mu.Unlock()
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if b.calls[p]--; b.calls[p] == 0 {
+			delete(b.calls, p)
+			b.drained.Broadcast()
+		}
+	}
+}
+
+// drain waits until p has no calls in flight.
+func (b *backend) drain(p *proxy) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.calls[p] > 0 {
+		b.drained.Wait()
+	}
+}
+
+// requester picks the frontend session that should answer a backend's request:
+// that of the only attached proxy, or else of the only proxy with calls in flight.
+// Anything else is ambiguous, and guessing could leak one user's request to another.
+func (b *backend) requester() (*mcp.ServerSession, error) {
+	b.mu.Lock()
+	var p *proxy
+	switch {
+	case len(b.proxies) == 1:
+		for attached := range b.proxies {
+			p = attached
+		}
+	case len(b.calls) == 1:
+		for calling := range b.calls {
+			p = calling
+		}
+	}
+	b.mu.Unlock()
+
+	if p == nil {
+		return nil, errors.New("cannot determine which client the request is for")
+	}
+
+	session := p.frontend()
+	if session == nil {
+		return nil, errors.New("client session is not initialized")
+	}
+	return session, nil
+}
+
+// createMessage forwards a backend's sampling request to the frontend.
+func (b *backend) createMessage(ctx context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	if !b.sampling.allow() {
+		return nil, fmt.Errorf("sampling rate limit exceeded for server %q", b.name)
+	}
+
+	session, err := b.requester()
+	if err != nil {
+		return nil, err
+	}
+	if params := session.InitializeParams(); params == nil || params.Capabilities == nil || params.Capabilities.Sampling == nil {
+		return nil, errors.New("client does not support sampling")
+	}
+
+	return session.CreateMessage(ctx, req.Params)
+}
+
+// elicit forwards a backend's elicitation request to the frontend.
+func (b *backend) elicit(ctx context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+	if !b.elicitation.allow() {
+		return nil, fmt.Errorf("elicitation rate limit exceeded for server %q", b.name)
+	}
+
+	session, err := b.requester()
+	if err != nil {
+		return nil, err
+	}
+	if params := session.InitializeParams(); params == nil || params.Capabilities == nil || params.Capabilities.Elicitation == nil {
+		return nil, errors.New("client does not support elicitation")
+	}
+
+	return session.Elicit(ctx, req.Params)
+}
@@ -0,0 +1,77 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// createSamplingServer returns a server whose "sample" tool asks the client to sample.
+func createSamplingServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "sampling", Version: "0.1.0"}, nil)
+	mcp.AddTool(
+		server,
+		&mcp.Tool{Name: "sample"},
+		func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, struct{}, error) {
+			res, err := req.Session.CreateMessage(ctx, &mcp.CreateMessageParams{
+				Messages:  []*mcp.SamplingMessage{{Role: "user", Content: &mcp.TextContent{Text: "hi"}}},
+				MaxTokens: 10,
+			})
+			if err != nil {
+				return nil, struct{}{}, err
+			}
+			return &mcp.CallToolResult{Content: []mcp.Content{res.Content}}, struct{}{}, nil
+		},
+	)
+	return server
+}
+
+var samplingClient = &mcp.ClientOptions{
+	CreateMessageHandler: func(context.Context, *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		return &mcp.CreateMessageResult{Role: "assistant", Model: "test", Content: &mcp.TextContent{Text: "sampled"}}, nil
+	},
+}
+
+func TestSamplingPassthrough(t *testing.T) {
+	ctx := context.Background()
+
+	options := Options{Sampling: Policy{Allow: true, PerMinute: 1}}
+	clients := Clients{"backend": WithOptions(&testClient{server: createSamplingServer()}, options)}
+	session := connectClient(ctx, t, newManager(&provider{clients: clients}), samplingClient)
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "backend.sample"})
+	if err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected sampling to succeed, got %v", result.Content)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; text != "sampled" {
+		t.Errorf("Expected 'sampled', got %q", text)
+	}
+
+	// The rate limit is one per minute
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "backend.sample"})
+	if err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected rate limited sampling to fail")
+	}
+}
+
+func TestSamplingDenied(t *testing.T) {
+	ctx := context.Background()
+
+	clients := Clients{"backend": &testClient{server: createSamplingServer()}}
+	session := connectClient(ctx, t, newManager(&provider{clients: clients}), samplingClient)
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "backend.sample"})
+	if err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected sampling to be denied by default")
+	}
+}
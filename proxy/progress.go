@@ -0,0 +1,74 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const progressTokenKey = "progressToken"
+
+// progressGrace is how long progress is still relayed after a request completes.
+const progressGrace = time.Second
+
+// progressTarget is where a backend's progress notifications are relayed.
+type progressTarget struct {
+	session *mcp.ServerSession
+	token   any
+}
+
+// forward prepares a frontend request's _meta for the backend.
+// Progress tokens are swapped for tokens unique to the backend, since
+// frontends sharing a backend may pick the same ones. The returned func
+// stops relaying progress, and must be called once the request completes.
+func (b *backend) forward(session *mcp.ServerSession, meta mcp.Meta) (mcp.Meta, func()) {
+	token, ok := meta[progressTokenKey]
+	if !ok || session == nil {
+		return meta, func() {}
+	}
+
+	backendToken := fmt.Sprintf("chimera-%d", b.tokens.Add(1))
+	meta = maps.Clone(meta)
+	meta[progressTokenKey] = backendToken
+
+	b.mu.Lock()
+	b.progress[backendToken] = progressTarget{session: session, token: token}
+	b.mu.Unlock()
+
+	return meta, func() {
+		// Notifications are handled concurrently with responses,
+		// so the last ones may trail the response a little
+		time.AfterFunc(progressGrace, func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.progress, backendToken)
+		})
+	}
+}
+
+// relayProgress sends a backend's progress notification to the frontend
+// that made the request, restoring the frontend's progress token.
+func (b *backend) relayProgress(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+	token, ok := req.Params.ProgressToken.(string)
+	if !ok {
+		return
+	}
+
+	b.mu.Lock()
+	target, ok := b.progress[token]
+	b.mu.Unlock()
+	if !ok {
+		// Request already completed
+		return
+	}
+
+	params := *req.Params
+	params.ProgressToken = target.token
+	if err := target.session.NotifyProgress(ctx, &params); err != nil {
+		slog.Error("failed to relay progress", "name", b.name, "err", err)
+	}
+}
@@ -0,0 +1,103 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// createLongServer returns a server with a "work" tool that reports progress,
+// and a "block" tool that runs until cancelled.
+func createLongServer(cancelled chan<- struct{}) *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "long", Version: "0.1.0"}, nil)
+	mcp.AddTool(
+		server,
+		&mcp.Tool{Name: "work"},
+		func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, struct{}, error) {
+			token := req.Params.GetProgressToken()
+			for i := range 3 {
+				err := req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+					ProgressToken: token,
+					Progress:      float64(i + 1),
+					Total:         3,
+				})
+				if err != nil {
+					return nil, struct{}{}, err
+				}
+			}
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprint(token)}}}, struct{}{}, nil
+		},
+	)
+	mcp.AddTool(
+		server,
+		&mcp.Tool{Name: "block"},
+		func(ctx context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, struct{}, error) {
+			<-ctx.Done()
+			cancelled <- struct{}{}
+			return nil, struct{}{}, ctx.Err()
+		},
+	)
+	return server
+}
+
+func TestProgressForwarding(t *testing.T) {
+	ctx := context.Background()
+
+	progress := make(chan *mcp.ProgressNotificationParams, 3)
+	opts := &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+			progress <- req.Params
+		},
+	}
+
+	clients := Clients{"backend": &testClient{server: createLongServer(nil)}}
+	session := connectClient(ctx, t, newManager(&provider{clients: clients}), opts)
+
+	params := &mcp.CallToolParams{
+		Meta: mcp.Meta{"progressToken": "frontend-token"},
+		Name: "backend.work",
+	}
+	result, err := session.CallTool(ctx, params)
+	if err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+
+	// The backend sees its own token
+	if text := result.Content[0].(*mcp.TextContent).Text; text == "frontend-token" {
+		t.Errorf("Expected backend token to be remapped, got %q", text)
+	}
+
+	for i := range 3 {
+		select {
+		case p := <-progress:
+			if p.ProgressToken != "frontend-token" || p.Progress != float64(i+1) {
+				t.Errorf("Unexpected progress %+v", p)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for progress %d", i+1)
+		}
+	}
+}
+
+func TestCancellationForwarding(t *testing.T) {
+	ctx := context.Background()
+
+	cancelled := make(chan struct{}, 1)
+	clients := Clients{"backend": &testClient{server: createLongServer(cancelled)}}
+	session := connectProxyClient(ctx, t, clients)
+
+	callCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if _, err := session.CallTool(callCtx, &mcp.CallToolParams{Name: "backend.block"}); err == nil {
+		t.Fatal("Expected cancelled call to fail")
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Backend tool was not cancelled")
+	}
+}
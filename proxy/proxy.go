@@ -2,10 +2,15 @@ package proxy
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"net/url"
 	"sync"
+	"sync/atomic"
 
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yosida95/uritemplate/v3"
 )
 
 // Client connects to an MCP server and returns a session.
@@ -15,86 +20,194 @@ type Client interface {
 
 type proxy struct {
 	server *mcp.Server
+	// authorizer, if set, limits what the caller identified by info may use
+	authorizer Authorizer
+	info       *mcpauth.TokenInfo
+
+	// clients provides the clients the session uses
+	clients func() Clients
+	// live is set when backends may be added after initialize
+	live bool
+	// reloadMu serializes changes to the set of backends
+	reloadMu sync.Mutex
+	// rootsMu serializes refreshes of roots, which are due once rootsStale is set
+	rootsMu    sync.Mutex
+	rootsStale atomic.Bool
+
+	mu sync.Mutex
+	// backends maps prefixes to the backends that own them
+	backends map[string]*backend
+	// roots are the frontend session's roots
+	roots []*mcp.Root
+	// session is the frontend session, once initialized
+	session *mcp.ServerSession
+	// logLevel is the level the frontend asked for
+	logLevel mcp.LoggingLevel
+
+	// exposed names claimed by each backend
+	tools     *claims
+	prompts   *claims
+	resources *claims
+	templates *claims
 }
 
+func newProxy() *proxy {
+	return &proxy{
+		backends:  make(map[string]*backend),
+		tools:     newClaims(),
+		prompts:   newClaims(),
+		resources: newClaims(),
+		templates: newClaims(),
+	}
+}
+
+// cache tracks the names registered for one backend.
 type cache struct {
 	sync.Mutex
-	names map[string]bool
+	// session the names were registered against
+	session *mcp.ClientSession
+	names   map[string]bool
 }
 
-// proxyServer connects to a backend and registers its tools, resources, and prompts.
-func (p *proxy) proxyServer(ctx context.Context, name string, client Client) {
-	transport := client.Transport(ctx)
-	if transport == nil {
-		slog.Error("no transport available for client", "name", name)
-		return
-	}
+// mark records name as present, and reports whether it must be (re)registered.
+// Names registered against an older session are always re-registered so their
+// handlers use the live session.
+func (c *cache) mark(name string, session *mcp.ClientSession) bool {
+	_, ok := c.names[name]
+	c.names[name] = true
+	return !ok || c.session != session
+}
 
-	tools := &cache{
-		names: make(map[string]bool),
-	}
-	prompts := &cache{
-		names: make(map[string]bool),
+// sweep returns the names not marked since the last sweep, and forgets them.
+func (c *cache) sweep(session *mcp.ClientSession) []string {
+	c.session = session
+
+	var rm []string
+	for name, registered := range c.names {
+		if !registered {
+			rm = append(rm, name)
+			delete(c.names, name)
+		} else {
+			// reset for next iteration
+			c.names[name] = false
+		}
 	}
-	resources := &cache{
-		names: make(map[string]bool),
+	return rm
+}
+
+// clear forgets every name, and returns them.
+func (c *cache) clear() []string {
+	c.Lock()
+	defer c.Unlock()
+
+	var rm []string
+	for name := range c.names {
+		rm = append(rm, name)
 	}
+	clear(c.names)
+	c.session = nil
+	return rm
+}
 
-	c := mcp.NewClient(&mcp.Implementation{
-		Name: "chimera",
-	}, &mcp.ClientOptions{
-		ToolListChangedHandler: func(ctx context.Context, req *mcp.ToolListChangedRequest) {
-			p.proxyTools(ctx, name, req.Session, tools)
-		},
-		PromptListChangedHandler: func(ctx context.Context, req *mcp.PromptListChangedRequest) {
-			p.proxyPrompts(ctx, name, req.Session, prompts)
-		},
-		ResourceListChangedHandler: func(ctx context.Context, req *mcp.ResourceListChangedRequest) {
-			p.proxyResources(ctx, name, req.Session, resources)
-		},
+// proxyServer attaches to a backend and registers its tools, resources, and prompts.
+// If the backend is down, its capabilities are registered once it reconnects.
+func (p *proxy) proxyServer(ctx context.Context, b *backend) {
+	caches := b.attach(p)
 
-		// TODO
-		ResourceUpdatedHandler: nil,
-	})
+	p.mu.Lock()
+	p.backends[b.prefix] = b
+	p.mu.Unlock()
 
-	session, err := c.Connect(ctx, transport, nil)
+	// Detach when the frontend session ends
+	go func() {
+		<-ctx.Done()
+		b.detach(p)
+	}()
+
+	session, err := b.connect()
 	if err != nil {
-		slog.Error("failed to connect to server", "name", name, "err", err)
+		slog.Error("failed to connect to server", "name", b.name, "err", err)
 		return
 	}
+	b.updateLevel(ctx)
 
-	// Close session when context is cancelled
-	go func() {
-		<-ctx.Done()
-		if err := session.Close(); err != nil {
-			slog.Error("failed to close session", "name", name, "err", err)
-		}
-	}()
+	p.sync(ctx, b, session, caches)
+}
+
+// sync registers everything a backend session currently offers.
+func (p *proxy) sync(ctx context.Context, b *backend, session *mcp.ClientSession, caches *caches) {
+	// Only list what the backend advertises
+	caps := capabilities(session)
 
 	wg := sync.WaitGroup{}
-	wg.Go(func() {
-		p.proxyTools(ctx, name, session, tools)
-	})
-	wg.Go(func() {
-		p.proxyPrompts(ctx, name, session, prompts)
-	})
-	wg.Go(func() {
-		p.proxyResources(ctx, name, session, resources)
-	})
+	if caps.Tools != nil {
+		wg.Go(func() {
+			p.proxyTools(ctx, b, session, caches.tools)
+		})
+	}
+	if caps.Prompts != nil {
+		wg.Go(func() {
+			p.proxyPrompts(ctx, b, session, caches.prompts)
+		})
+	}
+	if caps.Resources != nil {
+		wg.Go(func() {
+			p.proxyResources(ctx, b, session, caches.resources)
+		})
+		wg.Go(func() {
+			p.proxyResourceTemplates(ctx, b, session, caches.templates)
+		})
+	}
 	wg.Wait()
 }
 
-func (p *proxy) proxyTools(ctx context.Context, name string, session *mcp.ClientSession, cache *cache) {
-	// Gather prompts
+// frontend returns the frontend session, or nil if it has not initialized.
+func (p *proxy) frontend() *mcp.ServerSession {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.session
+}
+
+// capabilities returns the capabilities a backend session advertised.
+func capabilities(session *mcp.ClientSession) *mcp.ServerCapabilities {
+	if res := session.InitializeResult(); res != nil && res.Capabilities != nil {
+		return res.Capabilities
+	}
+	return &mcp.ServerCapabilities{}
+}
+
+// unregister removes everything a backend registered.
+func (p *proxy) unregister(b *backend, caches *caches) {
+	tools := caches.tools.clear()
+	p.tools.release(b.name, tools...)
+	p.server.RemoveTools(tools...)
+
+	prompts := caches.prompts.clear()
+	p.prompts.release(b.name, prompts...)
+	p.server.RemovePrompts(prompts...)
+
+	resources := caches.resources.clear()
+	p.resources.release(b.name, resources...)
+	p.server.RemoveResources(resources...)
+
+	templates := caches.templates.clear()
+	p.templates.release(b.name, templates...)
+	p.server.RemoveResourceTemplates(templates...)
+}
+
+func (p *proxy) proxyTools(ctx context.Context, b *backend, session *mcp.ClientSession, cache *cache) {
+	// Gather tools
 	var tools []*mcp.Tool
 	for tool, err := range session.Tools(ctx, nil) {
 		if err != nil {
 			// Connection failure
-			slog.Error("failed to list prompts", "name", name, "err", err)
+			slog.Error("failed to list tools", "name", b.name, "err", err)
 			return
 		}
 
-		tools = append(tools, tool)
+		if b.options.Tools.Allows(tool.Name) {
+			tools = append(tools, tool)
+		}
 	}
 
 	cache.Lock()
@@ -104,72 +217,218 @@ func (p *proxy) proxyTools(ctx context.Context, name string, session *mcp.Client
 	for _, tool := range tools {
 		// Prefix name for uniqueness, but save name for callback
 		oldName := tool.Name
-		tool.Name = name + "." + tool.Name
+		tool.Name = b.expose(tool.Name)
+
+		if !p.allows(KindTool, tool.Name) {
+			continue
+		}
+
+		overlay := b.options.Overlays[oldName]
+		overlay.apply(tool)
 
-		if _, ok := cache.names[tool.Name]; ok {
+		var validator *outputValidator
+		if overlay.ValidateOutput {
+			v, err := newOutputValidator(tool)
+			if err != nil {
+				slog.Error("invalid output schema", "name", tool.Name, "backend", b.name, "err", err)
+			}
+			validator = v
+		}
+
+		if owner, ok := p.tools.claim(b.name, tool.Name, oldName); !ok {
+			slog.Error("tool name collision", "name", tool.Name, "backend", b.name, "owner", owner)
+			continue
+		}
+
+		if !cache.mark(tool.Name, session) {
 			// Already registered
-			cache.names[tool.Name] = true
 			continue
 		}
-		// mark as registered
-		cache.names[tool.Name] = true
 
 		p.server.AddTool(tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if err := p.authorize(req.Extra, KindTool, tool.Name); err != nil {
+				return nil, err
+			}
+
+			meta, done := b.forward(req.Session, req.Params.Meta)
+			defer done()
+
 			params := &mcp.CallToolParams{
+				Meta:      meta,
 				Name:      oldName,
 				Arguments: req.Params.Arguments,
 			}
 
-			return session.CallTool(ctx, params)
+			defer b.track(p)()
+			res, err := session.CallTool(ctx, params)
+			if err == nil && validator != nil {
+				if err := validator.validate(res); err != nil {
+					return nil, fmt.Errorf("tool %q returned invalid structured content: %w", tool.Name, err)
+				}
+			}
+			return res, err
 		})
 	}
 
-	// Unregister prompts that are no longer present
-	var rm []string
-	for name, registered := range cache.names {
-		if !registered {
-			rm = append(rm, name)
-			delete(cache.names, name)
-		} else {
-			// reset for next iteration
-			cache.names[name] = false
+	// Unregister tools that are no longer present
+	rm := cache.sweep(session)
+	p.tools.release(b.name, rm...)
+	p.server.RemoveTools(rm...)
+}
+
+func (p *proxy) proxyResources(ctx context.Context, b *backend, session *mcp.ClientSession, cache *cache) {
+	// Gather resources
+	var resources []*mcp.Resource
+	for resource, err := range session.Resources(ctx, nil) {
+		if err != nil {
+			// Connection failure
+			slog.Error("failed to list resources", "name", b.name, "err", err)
+			return
+		}
+
+		if b.options.Resources.Allows(resource.URI) {
+			resources = append(resources, resource)
 		}
 	}
-	p.server.RemovePrompts(rm...)
+
+	cache.Lock()
+	defer cache.Unlock()
+
+	// Register resources
+	for _, resource := range resources {
+		// Rewrite URI into the proxy namespace, but save URI for callback
+		rewritten := *resource
+		rewritten.URI = rewriteURI(b.prefix, resource.URI)
+
+		if _, err := url.Parse(rewritten.URI); err != nil {
+			slog.Error("invalid resource URI", "uri", resource.URI, "backend", b.name, "err", err)
+			continue
+		}
+
+		if !p.allows(KindResource, rewritten.URI) {
+			continue
+		}
+
+		if owner, ok := p.resources.claim(b.name, rewritten.URI, resource.URI); !ok {
+			slog.Error("resource URI collision", "uri", rewritten.URI, "backend", b.name, "owner", owner)
+			continue
+		}
+
+		if !cache.mark(rewritten.URI, session) {
+			// Already registered
+			continue
+		}
+
+		p.server.AddResource(&rewritten, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			if err := p.authorize(req.Extra, KindResource, req.Params.URI); err != nil {
+				return nil, err
+			}
+
+			meta, done := b.forward(req.Session, req.Params.Meta)
+			defer done()
+
+			params := &mcp.ReadResourceParams{
+				Meta: meta,
+				URI:  resource.URI,
+			}
+
+			defer b.track(p)()
+			res, err := session.ReadResource(ctx, params)
+			return rewriteContents(b.prefix, res), err
+		})
+	}
+
+	// Unregister resources that are no longer present
+	rm := cache.sweep(session)
+	p.resources.release(b.name, rm...)
+	p.server.RemoveResources(rm...)
 }
 
-func (p *proxy) proxyResources(ctx context.Context, name string, session *mcp.ClientSession, cache *cache) {
-	for resource, err := range session.Resources(ctx, nil) {
+func (p *proxy) proxyResourceTemplates(ctx context.Context, b *backend, session *mcp.ClientSession, cache *cache) {
+	// Gather resource templates
+	var templates []*mcp.ResourceTemplate
+	for template, err := range session.ResourceTemplates(ctx, nil) {
 		if err != nil {
-			slog.Error("failed to list resources", "name", name, "err", err)
+			// Connection failure
+			slog.Error("failed to list resource templates", "name", b.name, "err", err)
 			return
 		}
 
-		// Prefix URI unless already prefixed
-		prefixedResource := *resource
-		prefixedResource.URI = name + "." + resource.URI
+		if b.options.Resources.Allows(template.URITemplate) {
+			templates = append(templates, template)
+		}
+	}
+
+	cache.Lock()
+	defer cache.Unlock()
+
+	// Register resource templates
+	for _, template := range templates {
+		// Rewrite template into the proxy namespace
+		rewritten := *template
+		rewritten.URITemplate = rewriteURI(b.prefix, template.URITemplate)
+
+		if _, err := uritemplate.New(rewritten.URITemplate); err != nil {
+			slog.Error("invalid resource template", "uri", template.URITemplate, "backend", b.name, "err", err)
+			continue
+		}
+
+		if !p.allows(KindResource, rewritten.URITemplate) {
+			continue
+		}
+
+		if owner, ok := p.templates.claim(b.name, rewritten.URITemplate, template.URITemplate); !ok {
+			slog.Error("resource template collision", "uri", rewritten.URITemplate, "backend", b.name, "owner", owner)
+			continue
+		}
+
+		if !cache.mark(rewritten.URITemplate, session) {
+			// Already registered
+			continue
+		}
+
+		p.server.AddResourceTemplate(&rewritten, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			uri, ok := restoreURI(b.prefix, req.Params.URI)
+			if !ok {
+				return nil, mcp.ResourceNotFoundError(req.Params.URI)
+			}
+			if err := p.authorize(req.Extra, KindResource, req.Params.URI); err != nil {
+				return nil, err
+			}
+
+			meta, done := b.forward(req.Session, req.Params.Meta)
+			defer done()
 
-		p.server.AddResource(&prefixedResource, func(ctx context.Context, _ *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
 			params := &mcp.ReadResourceParams{
-				URI: resource.URI,
+				Meta: meta,
+				URI:  uri,
 			}
 
-			return session.ReadResource(ctx, params)
+			defer b.track(p)()
+			res, err := session.ReadResource(ctx, params)
+			return rewriteContents(b.prefix, res), err
 		})
 	}
+
+	// Unregister resource templates that are no longer present
+	rm := cache.sweep(session)
+	p.templates.release(b.name, rm...)
+	p.server.RemoveResourceTemplates(rm...)
 }
 
-func (p *proxy) proxyPrompts(ctx context.Context, name string, session *mcp.ClientSession, cache *cache) {
+func (p *proxy) proxyPrompts(ctx context.Context, b *backend, session *mcp.ClientSession, cache *cache) {
 	// Gather prompts
 	var prompts []*mcp.Prompt
 	for prompt, err := range session.Prompts(ctx, nil) {
 		if err != nil {
 			// Connection failure
-			slog.Error("failed to list prompts", "name", name, "err", err)
+			slog.Error("failed to list prompts", "name", b.name, "err", err)
 			return
 		}
 
-		prompts = append(prompts, prompt)
+		if b.options.Prompts.Allows(prompt.Name) {
+			prompts = append(prompts, prompt)
+		}
 	}
 
 	cache.Lock()
@@ -179,36 +438,43 @@ func (p *proxy) proxyPrompts(ctx context.Context, name string, session *mcp.Clie
 	for _, prompt := range prompts {
 		// Prefix name for uniqueness, but save name for callback
 		oldName := prompt.Name
-		prompt.Name = name + "." + prompt.Name
+		prompt.Name = b.expose(prompt.Name)
+
+		if !p.allows(KindPrompt, prompt.Name) {
+			continue
+		}
 
-		if _, ok := cache.names[prompt.Name]; ok {
+		if owner, ok := p.prompts.claim(b.name, prompt.Name, oldName); !ok {
+			slog.Error("prompt name collision", "name", prompt.Name, "backend", b.name, "owner", owner)
+			continue
+		}
+
+		if !cache.mark(prompt.Name, session) {
 			// Already registered
-			cache.names[prompt.Name] = true
 			continue
 		}
-		// mark as registered
-		cache.names[prompt.Name] = true
 
 		p.server.AddPrompt(prompt, func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			if err := p.authorize(req.Extra, KindPrompt, prompt.Name); err != nil {
+				return nil, err
+			}
+
+			meta, done := b.forward(req.Session, req.Params.Meta)
+			defer done()
+
 			params := &mcp.GetPromptParams{
+				Meta:      meta,
 				Name:      oldName,
 				Arguments: req.Params.Arguments,
 			}
 
+			defer b.track(p)()
 			return session.GetPrompt(ctx, params)
 		})
 	}
 
 	// Unregister prompts that are no longer present
-	var rm []string
-	for name, registered := range cache.names {
-		if !registered {
-			rm = append(rm, name)
-			delete(cache.names, name)
-		} else {
-			// reset for next iteration
-			cache.names[name] = false
-		}
-	}
+	rm := cache.sweep(session)
+	p.prompts.release(b.name, rm...)
 	p.server.RemovePrompts(rm...)
 }
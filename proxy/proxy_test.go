@@ -3,9 +3,11 @@ package proxy
 import (
 	"context"
 	"fmt"
+	"slices"
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/njayp/chimera/internal/testutil"
 )
 
 type testClient struct {
@@ -75,9 +77,19 @@ func (p *provider) Clients() Clients {
 
 func connectProxyClient(ctx context.Context, t *testing.T, clients Clients) *mcp.ClientSession {
 	t.Helper()
+	return connectManagerClient(ctx, t, newManager(&provider{clients: clients}))
+}
 
-	m := &manager{provider: &provider{clients: clients}}
-	proxyServer := m.newProxy(ctx)
+func connectManagerClient(ctx context.Context, t *testing.T, m *manager) *mcp.ClientSession {
+	t.Helper()
+	return connectClient(ctx, t, m, nil)
+}
+
+// connectClient connects a frontend client with the given options to a new proxy.
+func connectClient(ctx context.Context, t *testing.T, m *manager, opts *mcp.ClientOptions) *mcp.ClientSession {
+	t.Helper()
+
+	proxyServer := m.newProxy(ctx, m.provider.Clients)
 
 	serverTransport, clientTransport := mcp.NewInMemoryTransports()
 
@@ -85,7 +97,7 @@ func connectProxyClient(ctx context.Context, t *testing.T, clients Clients) *mcp
 		t.Fatalf("Failed to connect proxy server: %v", err)
 	}
 
-	client := mcp.NewClient(&mcp.Implementation{Name: "proxy-client", Version: "0.1.0"}, nil)
+	client := mcp.NewClient(&mcp.Implementation{Name: "proxy-client", Version: "0.1.0"}, opts)
 	session, err := client.Connect(ctx, clientTransport, nil)
 	if err != nil {
 		t.Fatalf("Failed to connect proxy client: %v", err)
@@ -140,11 +152,11 @@ func TestProxySingleBackend(t *testing.T) {
 			resources = append(resources, resource)
 		}
 
-		if len(resources) != 1 || resources[0].URI != "backend.test://data" {
-			t.Errorf("Expected resource 'backend.test://data', got %d resources", len(resources))
+		if len(resources) != 1 || resources[0].URI != "chimera://backend/test://data" {
+			t.Errorf("Expected resource 'chimera://backend/test://data', got %d resources", len(resources))
 		}
 
-		result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "backend.test://data"})
+		result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "chimera://backend/test://data"})
 		if err != nil {
 			t.Fatalf("Failed to read resource: %v", err)
 		}
@@ -271,3 +283,103 @@ func TestProxyPrefixing(t *testing.T) {
 		t.Errorf("Expected tool 'backend.prefixed' (no double-prefix), got %q", tools[0].Name)
 	}
 }
+
+func TestProxyFilters(t *testing.T) {
+	ctx := context.Background()
+
+	server := createTestServer("test-server")
+	mcp.AddTool(
+		server,
+		&mcp.Tool{Name: "write_file"},
+		func(_ context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, struct{}, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, struct{}{}, nil
+		},
+	)
+
+	options := Options{
+		Tools:   Filter{Exclude: []string{"write_*"}},
+		Prompts: Filter{Include: []string{"farewell"}},
+	}
+	clients := Clients{"backend": WithOptions(&testClient{server: server}, options)}
+	session := connectProxyClient(ctx, t, clients)
+
+	if names := toolNames(ctx, t, session); len(names) != 1 || names[0] != "backend.echo" {
+		t.Errorf("Expected only tool 'backend.echo', got %v", names)
+	}
+
+	var prompts []*mcp.Prompt
+	for prompt, err := range session.Prompts(ctx, nil) {
+		if err != nil {
+			t.Fatalf("Failed to list prompts: %v", err)
+		}
+		prompts = append(prompts, prompt)
+	}
+
+	if len(prompts) != 0 {
+		t.Errorf("Expected no prompts, got %d", len(prompts))
+	}
+
+	// Refreshes are filtered too
+	for _, name := range []string{"write_dir", "read_dir"} {
+		mcp.AddTool(
+			server,
+			&mcp.Tool{Name: name},
+			func(_ context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, struct{}, error) {
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, struct{}{}, nil
+			},
+		)
+	}
+
+	// Any refresh that brings read_dir also lists write_dir, added before it
+	var names []string
+	refreshed := testutil.Eventually(func() bool {
+		names = toolNames(ctx, t, session)
+		return slices.Contains(names, "backend.read_dir")
+	})
+	if !refreshed || len(names) != 2 {
+		t.Errorf("Expected filtered refresh, got %v", names)
+	}
+}
+
+func TestProxyResourceTemplates(t *testing.T) {
+	ctx := context.Background()
+
+	server := createTestServer("test-server")
+	server.AddResourceTemplate(
+		&mcp.ResourceTemplate{Name: "file", URITemplate: "test://files/{name}"},
+		func(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{
+				Contents: []*mcp.ResourceContents{{URI: req.Params.URI, Text: "contents of " + req.Params.URI}},
+			}, nil
+		},
+	)
+
+	clients := Clients{"backend": &testClient{server: server}}
+	session := connectProxyClient(ctx, t, clients)
+
+	var templates []*mcp.ResourceTemplate
+	for template, err := range session.ResourceTemplates(ctx, nil) {
+		if err != nil {
+			t.Fatalf("Failed to list resource templates: %v", err)
+		}
+		templates = append(templates, template)
+	}
+
+	if len(templates) != 1 || templates[0].URITemplate != "chimera://backend/test://files/{name}" {
+		t.Fatalf("Expected template 'chimera://backend/test://files/{name}', got %d templates", len(templates))
+	}
+
+	result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "chimera://backend/test://files/readme"})
+	if err != nil {
+		t.Fatalf("Failed to read resource: %v", err)
+	}
+
+	// The backend sees the original URI, and the client the rewritten one
+	contents := result.Contents[0]
+	if contents.Text != "contents of test://files/readme" {
+		t.Errorf("Expected original URI at backend, got %q", contents.Text)
+	}
+	if contents.URI != "chimera://backend/test://files/readme" {
+		t.Errorf("Expected rewritten URI, got %q", contents.URI)
+	}
+}
@@ -0,0 +1,53 @@
+package proxy
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// Diff lists the servers that differ between two sets of clients.
+type Diff struct {
+	Added   []string
+	Changed []string
+	Removed []string
+}
+
+// Empty reports whether nothing changed.
+func (d Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Changed) == 0 && len(d.Removed) == 0
+}
+
+// Reconcile compares freshly loaded clients with the previous ones.
+// It returns next with every unchanged client replaced by its previous
+// instance, so that backends keyed on the instance, such as shared ones,
+// keep their sessions. Clients are compared by value, so providers should
+// build them deterministically.
+//
+// Reconcile only decides which instances to keep. Handler retires the
+// backends of changed and removed clients, once their calls in flight
+// finish, when the provider is a Notifier.
+func Reconcile(prev, next Clients) (Clients, Diff) {
+	var diff Diff
+	clients := make(Clients, len(next))
+	for _, name := range slices.Sorted(maps.Keys(next)) {
+		client := next[name]
+		old, ok := prev[name]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, name)
+		case reflect.DeepEqual(old, client):
+			client = old
+		default:
+			diff.Changed = append(diff.Changed, name)
+		}
+		clients[name] = client
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(prev)) {
+		if _, ok := next[name]; !ok {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+	return clients, diff
+}
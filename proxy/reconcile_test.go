@@ -0,0 +1,109 @@
+package proxy
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/njayp/chimera/internal/testutil"
+)
+
+// urlClient is compared by value, like the clients built from config files.
+type urlClient struct {
+	url string
+}
+
+func (c *urlClient) Transport(context.Context) mcp.Transport {
+	return nil
+}
+
+func TestReconcile(t *testing.T) {
+	prev := Clients{
+		"same":    WithOptions(&urlClient{"http://a"}, Options{Shared: true}),
+		"changed": WithOptions(&urlClient{"http://b"}, Options{}),
+		"removed": &urlClient{"http://c"},
+	}
+	next := Clients{
+		"same":    WithOptions(&urlClient{"http://a"}, Options{Shared: true}),
+		"changed": WithOptions(&urlClient{"http://b"}, Options{Alias: "b"}),
+		"added":   &urlClient{"http://d"},
+	}
+
+	clients, diff := Reconcile(prev, next)
+	if clients["same"] != prev["same"] {
+		t.Error("expected the unchanged client to be reused")
+	}
+	if clients["changed"] != next["changed"] || clients["added"] != next["added"] {
+		t.Error("expected new and changed clients to be taken from next")
+	}
+	if len(clients) != 3 {
+		t.Errorf("expected 3 clients, got %d", len(clients))
+	}
+
+	if !slices.Equal(diff.Added, []string{"added"}) ||
+		!slices.Equal(diff.Changed, []string{"changed"}) ||
+		!slices.Equal(diff.Removed, []string{"removed"}) {
+		t.Errorf("unexpected diff %+v", diff)
+	}
+
+	if _, diff := Reconcile(clients, clients); !diff.Empty() {
+		t.Errorf("expected no changes, got %+v", diff)
+	}
+}
+
+func TestReconcileRetiresBackends(t *testing.T) {
+	ctx := context.Background()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	slow := mcp.NewServer(&mcp.Implementation{Name: "slow"}, nil)
+	slow.AddTool(&mcp.Tool{Name: "wait", InputSchema: map[string]any{"type": "object"}}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-release
+		return &mcp.CallToolResult{}, nil
+	})
+
+	shared := Options{Shared: true}
+	changed := &testClient{server: slow}
+	removed := &testClient{server: createTestServer("removed")}
+	provider := &notifyingProvider{clients: Clients{
+		"changed": WithOptions(changed, shared),
+		"removed": WithOptions(removed, shared),
+	}}
+	session := connectClient(ctx, t, newManager(provider), nil)
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "changed.wait"})
+		result <- err
+	}()
+	<-started
+
+	next := Clients{"changed": WithOptions(&testClient{server: createTestServer("changed")}, shared)}
+	clients, diff := Reconcile(provider.Clients(), next)
+	if !slices.Equal(diff.Changed, []string{"changed"}) || !slices.Equal(diff.Removed, []string{"removed"}) {
+		t.Fatalf("unexpected diff %+v", diff)
+	}
+	provider.set(clients)
+
+	// The removed backend shuts down, and the changed one waits for its call
+	if !testutil.Eventually(func() bool { return removed.sessions() == 0 }) {
+		t.Fatal("expected the removed backend to be closed")
+	}
+	if n := changed.sessions(); n != 1 {
+		t.Errorf("expected the changed backend to stay while its call is in flight, got %d sessions", n)
+	}
+
+	close(release)
+	if err := <-result; err != nil {
+		t.Fatalf("expected the call in flight to complete, got %v", err)
+	}
+	if !testutil.Eventually(func() bool { return changed.sessions() == 0 }) {
+		t.Fatal("expected the changed backend to be closed once its call finished")
+	}
+	if !testutil.Eventually(func() bool {
+		return slices.Equal(toolNames(ctx, t, session), []string{"changed.echo"})
+	}) {
+		t.Fatalf("expected the replacement's tools, got %v", toolNames(ctx, t, session))
+	}
+}
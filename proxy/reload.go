@@ -0,0 +1,111 @@
+package proxy
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"sync"
+)
+
+// Notifier is a Provider whose clients change over time, such as a config
+// file watcher. Handler subscribes to it, so that sessions already connected
+// gain and lose backends as the clients change, instead of keeping the
+// clients they started with.
+type Notifier interface {
+	Provider
+	// Subscribe calls notify after each change to the clients, until ctx is done.
+	Subscribe(ctx context.Context, notify func())
+}
+
+// reload brings every live session in line with the provider's clients.
+func (m *manager) reload() {
+	m.pool.prune(m.provider.Clients())
+
+	m.mu.Lock()
+	sessions := maps.Clone(m.sessions)
+	m.mu.Unlock()
+
+	for p, ctx := range sessions {
+		go p.reload(ctx, m)
+	}
+}
+
+// track records a live session until ctx is done.
+func (m *manager) track(ctx context.Context, p *proxy) {
+	m.mu.Lock()
+	m.sessions[p] = ctx
+	m.mu.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		m.mu.Lock()
+		delete(m.sessions, p)
+		m.mu.Unlock()
+	})
+}
+
+// reload detaches the backends whose clients were removed or replaced,
+// then attaches backends for the clients the session lacks.
+func (p *proxy) reload(ctx context.Context, m *manager) {
+	p.reloadMu.Lock()
+	defer p.reloadMu.Unlock()
+	if ctx.Err() != nil {
+		return
+	}
+
+	// Read the clients under the lock, so overlapping reloads
+	// cannot apply an older set after a newer one
+	clients := p.clients()
+
+	var stale []*backend
+	current := make(map[string]bool)
+	p.mu.Lock()
+	for _, b := range p.backends {
+		if client, ok := clients[b.name]; ok && client == b.client {
+			current[b.name] = true
+			continue
+		}
+		stale = append(stale, b)
+	}
+	p.mu.Unlock()
+
+	wg := sync.WaitGroup{}
+	for _, b := range stale {
+		wg.Go(func() {
+			p.drop(b)
+		})
+	}
+	// Replacements reuse the names of the backends they replace
+	wg.Wait()
+
+	for name, client := range clients {
+		if current[name] {
+			continue
+		}
+		slog.Info("attaching server to session", "name", name)
+		wg.Go(func() {
+			p.proxyServer(ctx, m.backend(ctx, name, client))
+		})
+	}
+	wg.Wait()
+}
+
+// drop detaches a backend once the session's calls to it have finished,
+// and removes everything it registered.
+func (p *proxy) drop(b *backend) {
+	b.drain(p)
+
+	c, ok := b.attached()[p]
+	if !ok {
+		return
+	}
+	b.detach(p)
+
+	p.mu.Lock()
+	if p.backends[b.prefix] == b {
+		delete(p.backends, b.prefix)
+	}
+	p.mu.Unlock()
+
+	// Removing the names notifies the frontend that its lists changed
+	p.unregister(b, c)
+}
@@ -0,0 +1,121 @@
+package proxy
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/njayp/chimera/internal/testutil"
+)
+
+// notifyingProvider is a Provider whose clients tests change.
+type notifyingProvider struct {
+	mu      sync.Mutex
+	clients Clients
+	notify  []func()
+}
+
+func (p *notifyingProvider) Clients() Clients {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.clients
+}
+
+func (p *notifyingProvider) Subscribe(_ context.Context, notify func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.notify = append(p.notify, notify)
+}
+
+func (p *notifyingProvider) set(clients Clients) {
+	p.mu.Lock()
+	p.clients = clients
+	notify := slices.Clone(p.notify)
+	p.mu.Unlock()
+
+	for _, f := range notify {
+		f()
+	}
+}
+
+func TestLiveReload(t *testing.T) {
+	ctx := context.Background()
+	a := &testClient{server: createTestServer("a")}
+	provider := &notifyingProvider{clients: Clients{}}
+
+	var changes atomic.Int32
+	session := connectClient(ctx, t, newManager(provider), &mcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *mcp.ToolListChangedRequest) {
+			changes.Add(1)
+		},
+	})
+
+	// Capabilities are advertised before any backend exists
+	caps := session.InitializeResult().Capabilities
+	if caps.Tools == nil || caps.Prompts == nil || caps.Resources == nil {
+		t.Fatalf("expected tools, prompts and resources to be advertised, got %+v", caps)
+	}
+
+	provider.set(Clients{"a": a})
+	if !testutil.Eventually(func() bool {
+		return slices.Equal(toolNames(ctx, t, session), []string{"a.echo"}) && changes.Load() > 0
+	}) {
+		t.Fatalf("expected a.echo to be added and announced, got %v", toolNames(ctx, t, session))
+	}
+	callEcho(ctx, t, session, "a.echo")
+
+	provider.set(Clients{"a": a, "b": &testClient{server: createTestServer("b")}})
+	if !testutil.Eventually(func() bool {
+		return slices.Equal(toolNames(ctx, t, session), []string{"a.echo", "b.echo"})
+	}) {
+		t.Fatalf("expected b.echo to be added, got %v", toolNames(ctx, t, session))
+	}
+
+	provider.set(Clients{"b": provider.Clients()["b"]})
+	if !testutil.Eventually(func() bool {
+		return slices.Equal(toolNames(ctx, t, session), []string{"b.echo"})
+	}) {
+		t.Fatalf("expected a.echo to be removed, got %v", toolNames(ctx, t, session))
+	}
+}
+
+func TestLiveReloadDrainsCalls(t *testing.T) {
+	ctx := context.Background()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	server := mcp.NewServer(&mcp.Implementation{Name: "slow"}, nil)
+	server.AddTool(&mcp.Tool{Name: "wait", InputSchema: map[string]any{"type": "object"}}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-release
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil
+	})
+
+	provider := &notifyingProvider{clients: Clients{"slow": &testClient{server: server}}}
+	session := connectClient(ctx, t, newManager(provider), nil)
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "slow.wait"})
+		result <- err
+	}()
+	<-started
+
+	// The call in flight survives the server's removal
+	provider.set(Clients{})
+	if names := toolNames(ctx, t, session); !slices.Equal(names, []string{"slow.wait"}) {
+		t.Errorf("expected slow.wait to stay while its call is in flight, got %v", names)
+	}
+
+	close(release)
+	if err := <-result; err != nil {
+		t.Fatalf("expected the call in flight to complete, got %v", err)
+	}
+	if !testutil.Eventually(func() bool {
+		return len(toolNames(ctx, t, session)) == 0
+	}) {
+		t.Fatalf("expected slow.wait to be removed, got %v", toolNames(ctx, t, session))
+	}
+}
@@ -0,0 +1,24 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+)
+
+type requestHeaderKey struct{}
+
+// withRequestHeader records the headers of the request that opened a frontend session.
+func withRequestHeader(ctx context.Context, header http.Header) context.Context {
+	return context.WithValue(ctx, requestHeaderKey{}, header)
+}
+
+// RequestHeader returns the headers of the HTTP request that opened the
+// frontend session. Clients receive this context when dialing backends
+// that are not shared, so they can forward the caller's credentials.
+func RequestHeader(ctx context.Context) http.Header {
+	header, _ := ctx.Value(requestHeaderKey{}).(http.Header)
+	if header == nil {
+		return http.Header{}
+	}
+	return header
+}
@@ -0,0 +1,123 @@
+package proxy
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// rootsTimeout bounds how long the frontend may take to list its roots.
+var rootsTimeout = 10 * time.Second
+
+// forwarded lists the methods that reach a backend.
+var forwarded = []string{"tools/call", "prompts/get", "resources/read", "completion/complete"}
+
+// rootsChanged marks the frontend's roots for a refresh before the next
+// request that reaches a backend, if the frontend has roots.
+func (p *proxy) rootsChanged(session *mcp.ServerSession) {
+	// The SDK decodes an empty roots capability like a missing one,
+	// so only clients that announce roots list changes are asked
+	params := session.InitializeParams()
+	if params == nil || params.Capabilities == nil || !params.Capabilities.Roots.ListChanged {
+		return
+	}
+	p.rootsStale.Store(true)
+}
+
+// interceptRoots is middleware that refreshes stale roots before a request
+// reaches a backend. Listing roots within the request sends roots/list on a
+// stream the frontend is reading, and never leaves it in flight once the
+// request is done, where it would hold up closing the session.
+func (p *proxy) interceptRoots(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if session, ok := req.GetSession().(*mcp.ServerSession); ok && slices.Contains(forwarded, method) {
+			p.refreshRoots(ctx, session)
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// refreshRoots fetches the frontend's roots, if stale, and pushes them to its backends.
+func (p *proxy) refreshRoots(ctx context.Context, session *mcp.ServerSession) {
+	p.rootsMu.Lock()
+	defer p.rootsMu.Unlock()
+	if !p.rootsStale.Swap(false) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, rootsTimeout)
+	defer cancel()
+
+	res, err := session.ListRoots(ctx, nil)
+	if err != nil {
+		// Keep the last roots until the frontend reports a change
+		slog.Warn("failed to list roots", "err", err)
+		return
+	}
+
+	p.mu.Lock()
+	p.roots = res.Roots
+	backends := slices.Collect(maps.Values(p.backends))
+	p.mu.Unlock()
+
+	for _, b := range backends {
+		b.updateRoots()
+	}
+}
+
+// frontendRoots returns the roots of the frontend session.
+func (p *proxy) frontendRoots() []*mcp.Root {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.roots
+}
+
+// updateRoots advertises the frontend's roots to the backend, minus those its
+// allowlist rejects. Shared backends serve every caller, so they see no roots,
+// since one caller's workspace must not leak to another.
+func (b *backend) updateRoots() {
+	b.rootsMu.Lock()
+	defer b.rootsMu.Unlock()
+
+	roots := make(map[string]*mcp.Root)
+	if !b.shared {
+		for p := range b.attached() {
+			for _, root := range p.frontendRoots() {
+				if b.options.Roots.Allows(root.URI) {
+					roots[root.URI] = root
+				}
+			}
+		}
+	}
+
+	b.mu.Lock()
+	c := b.mcpClient
+	b.mu.Unlock()
+
+	old := b.roots
+	b.roots = roots
+	if c == nil {
+		// Applied on the next connection
+		return
+	}
+
+	var removed []string
+	for uri := range old {
+		if _, ok := roots[uri]; !ok {
+			removed = append(removed, uri)
+		}
+	}
+	c.RemoveRoots(removed...)
+
+	var added []*mcp.Root
+	for uri, root := range roots {
+		if prev, ok := old[uri]; !ok || prev.Name != root.Name {
+			added = append(added, root)
+		}
+	}
+	c.AddRoots(added...)
+}
@@ -0,0 +1,194 @@
+package proxy
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/njayp/chimera/internal/testutil"
+)
+
+// connectWith connects client to a new proxy.
+func connectWith(ctx context.Context, t *testing.T, m *manager, client *mcp.Client) *mcp.ClientSession {
+	t.Helper()
+
+	proxyServer := m.newProxy(ctx, m.provider.Clients)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := proxyServer.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("Failed to connect proxy server: %v", err)
+	}
+
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect proxy client: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+// createRootsServer returns a server whose "roots" tool lists the client's roots.
+func createRootsServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "roots", Version: "0.1.0"}, nil)
+	mcp.AddTool(
+		server,
+		&mcp.Tool{Name: "roots"},
+		func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, struct{}, error) {
+			res, err := req.Session.ListRoots(ctx, nil)
+			if err != nil {
+				return nil, struct{}{}, err
+			}
+
+			var uris []string
+			for _, root := range res.Roots {
+				uris = append(uris, root.URI)
+			}
+			slices.Sort(uris)
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: strings.Join(uris, ",")}}}, struct{}{}, nil
+		},
+	)
+	return server
+}
+
+func TestRootsPropagation(t *testing.T) {
+	ctx := context.Background()
+
+	options := Options{Roots: Filter{Include: []string{"file:///work/*"}}}
+	clients := Clients{"backend": WithOptions(&testClient{server: createRootsServer()}, options)}
+	m := newManager(&provider{clients: clients})
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "proxy-client", Version: "0.1.0"}, nil)
+	client.AddRoots(
+		&mcp.Root{URI: "file:///work/app"},
+		&mcp.Root{URI: "file:///home/secret"},
+	)
+	session := connectWith(ctx, t, m, client)
+
+	roots := func() string {
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "backend.roots"})
+		if err != nil {
+			t.Fatalf("Failed to call tool: %v", err)
+		}
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	// Only allowed roots reach the backend
+	if !testutil.Eventually(func() bool { return roots() == "file:///work/app" }) {
+		t.Fatalf("Expected roots 'file:///work/app', got %q", roots())
+	}
+
+	// Changes on the frontend propagate
+	client.AddRoots(&mcp.Root{URI: "file:///work/lib"})
+	if !testutil.Eventually(func() bool { return roots() == "file:///work/app,file:///work/lib" }) {
+		t.Fatalf("Expected updated roots, got %q", roots())
+	}
+}
+
+func TestRootsListedWithRequests(t *testing.T) {
+	ctx := context.Background()
+	clients := Clients{"backend": &testClient{server: createRootsServer()}}
+	m := newManager(&provider{clients: clients})
+
+	var listed atomic.Int32
+	client := mcp.NewClient(&mcp.Implementation{Name: "proxy-client", Version: "0.1.0"}, nil)
+	client.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method == "roots/list" {
+				listed.Add(1)
+			}
+			return next(ctx, method, req)
+		}
+	})
+	client.AddRoots(&mcp.Root{URI: "file:///work/app"})
+	session := connectWith(ctx, t, m, client)
+
+	call := func() string {
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "backend.roots"})
+		if err != nil {
+			t.Fatalf("Failed to call tool: %v", err)
+		}
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	// Roots are only listed ahead of requests that reach a backend
+	if _, err := session.ListTools(ctx, nil); err != nil {
+		t.Fatalf("Failed to list tools: %v", err)
+	}
+	if n := listed.Load(); n != 0 {
+		t.Fatalf("Expected no roots/list before a call, got %d", n)
+	}
+	if got := call(); got != "file:///work/app" {
+		t.Errorf("Expected roots 'file:///work/app', got %q", got)
+	}
+	call()
+	if n := listed.Load(); n != 1 {
+		t.Errorf("Expected roots to be listed once, got %d", n)
+	}
+
+	// A change is listed before the next call
+	client.AddRoots(&mcp.Root{URI: "file:///work/lib"})
+	if !testutil.Eventually(func() bool { return call() == "file:///work/app,file:///work/lib" }) {
+		t.Errorf("Expected updated roots, got %q", call())
+	}
+}
+
+func TestRootsTimeout(t *testing.T) {
+	rootsTimeout = 100 * time.Millisecond
+	t.Cleanup(func() {
+		rootsTimeout = 10 * time.Second
+	})
+
+	ctx := context.Background()
+	clients := Clients{"backend": &testClient{server: createRootsServer()}}
+	m := newManager(&provider{clients: clients})
+
+	// The frontend never answers roots/list
+	release := make(chan struct{})
+	client := mcp.NewClient(&mcp.Implementation{Name: "proxy-client", Version: "0.1.0"}, nil)
+	client.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method == "roots/list" {
+				<-release
+			}
+			return next(ctx, method, req)
+		}
+	})
+	session := connectWith(ctx, t, m, client)
+	// Let the frontend close once the test is done
+	t.Cleanup(func() { close(release) })
+
+	start := time.Now()
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "backend.roots"}); err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the call to go ahead once roots timed out, took %v", elapsed)
+	}
+}
+
+func TestRootsNotShared(t *testing.T) {
+	ctx := context.Background()
+	clients := Clients{"backend": WithOptions(&testClient{server: createRootsServer()}, Options{Shared: true})}
+	m := newManager(&provider{clients: clients})
+
+	var sessions []*mcp.ClientSession
+	for _, uri := range []string{"file:///alice", "file:///bob"} {
+		client := mcp.NewClient(&mcp.Implementation{Name: "proxy-client", Version: "0.1.0"}, nil)
+		client.AddRoots(&mcp.Root{URI: uri})
+		sessions = append(sessions, connectWith(ctx, t, m, client))
+	}
+
+	// A shared backend must not see any caller's workspace
+	for _, session := range sessions {
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "backend.roots"})
+		if err != nil {
+			t.Fatalf("Failed to call tool: %v", err)
+		}
+		if got := result.Content[0].(*mcp.TextContent).Text; got != "" {
+			t.Errorf("Expected no roots for a shared backend, got %q", got)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Sessions may ask for a subset of the servers, by name, with a query
+// parameter or a header whose values are separated by commas, as in
+// ?servers=github,filesystem.
+const (
+	ServersParam  = "servers"
+	ServersHeader = "X-Chimera-Servers"
+)
+
+// RequestProvider is a Provider that picks the clients of each session
+// from the HTTP request that starts it.
+type RequestProvider interface {
+	Provider
+	// ClientsFor returns the clients of the session started by req.
+	// It is called again with the same request whenever a Notifier's
+	// clients change, long after req has been served.
+	// Clients should still return every client, since shared backends
+	// that it omits are shut down.
+	ClientsFor(req *http.Request) Clients
+}
+
+// RequestedServers returns the server names that req asks for,
+// preferring ServersParam to ServersHeader, or nil to ask for all.
+func RequestedServers(req *http.Request) []string {
+	if req == nil {
+		return nil
+	}
+
+	values := req.URL.Query()[ServersParam]
+	if len(values) == 0 {
+		values = req.Header.Values(ServersHeader)
+	}
+
+	var names []string
+	for _, value := range values {
+		for name := range strings.SplitSeq(value, ",") {
+			if name = strings.TrimSpace(name); name != "" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// selector returns what provides the clients of the session started by req.
+// Naming servers that are not configured is an error.
+func (m *manager) selector(req *http.Request) (func() Clients, error) {
+	if p, ok := m.provider.(RequestProvider); ok {
+		return func() Clients {
+			return p.ClientsFor(req)
+		}, nil
+	}
+
+	names := RequestedServers(req)
+	if names == nil {
+		return m.provider.Clients, nil
+	}
+
+	clients := m.provider.Clients()
+	var unknown []string
+	for _, name := range names {
+		if _, ok := clients[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown servers: %s", strings.Join(unknown, ", "))
+	}
+
+	// Select from the current clients, so that reloads apply
+	return func() Clients {
+		clients := m.provider.Clients()
+		selected := make(Clients, len(names))
+		for _, name := range names {
+			if client, ok := clients[name]; ok {
+				selected[name] = client
+			}
+		}
+		return selected
+	}, nil
+}
@@ -0,0 +1,128 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestRequestedServers(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		header []string
+		want   []string
+	}{
+		{name: "none", url: "/mcp"},
+		{name: "param", url: "/mcp?servers=github,%20filesystem,,github", want: []string{"github", "filesystem"}},
+		{name: "repeated param", url: "/mcp?servers=a&servers=b", want: []string{"a", "b"}},
+		{name: "header", url: "/mcp", header: []string{"a, b", "c"}, want: []string{"a", "b", "c"}},
+		{name: "param wins", url: "/mcp?servers=a", header: []string{"b"}, want: []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.url, nil)
+			for _, value := range tt.header {
+				req.Header.Add(ServersHeader, value)
+			}
+			if got := RequestedServers(req); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// connectHTTP opens a session through an HTTP handler.
+func connectHTTP(ctx context.Context, t *testing.T, url string, header http.Header) (*mcp.ClientSession, error) {
+	t.Helper()
+
+	transport := &mcp.StreamableClientTransport{
+		Endpoint:   url,
+		HTTPClient: &http.Client{Transport: headerTransport(header)},
+	}
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, transport, nil)
+	if err == nil {
+		t.Cleanup(func() { _ = session.Close() })
+	}
+	return session, err
+}
+
+// headerTransport adds headers to every request.
+type headerTransport http.Header
+
+func (h headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, values := range h {
+		req.Header[key] = values
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestSelectServers(t *testing.T) {
+	ctx := context.Background()
+	clients := Clients{
+		"a": &testClient{server: createTestServer("a")},
+		"b": &testClient{server: createTestServer("b")},
+		"c": &testClient{server: createTestServer("c")},
+	}
+	server := httptest.NewServer(Handler(&provider{clients: clients}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name   string
+		query  string
+		header http.Header
+		want   []string
+	}{
+		{name: "all", want: []string{"a.echo", "b.echo", "c.echo"}},
+		{name: "param", query: "?servers=a,c", want: []string{"a.echo", "c.echo"}},
+		{name: "header", header: http.Header{ServersHeader: {"b"}}, want: []string{"b.echo"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, err := connectHTTP(ctx, t, server.URL+tt.query, tt.header)
+			if err != nil {
+				t.Fatalf("Failed to connect: %v", err)
+			}
+			if names := toolNames(ctx, t, session); !slices.Equal(names, tt.want) {
+				t.Errorf("got %v, want %v", names, tt.want)
+			}
+		})
+	}
+
+	if _, err := connectHTTP(ctx, t, server.URL+"?servers=a,nope", nil); err == nil {
+		t.Error("Expected unknown servers to be rejected")
+	}
+}
+
+// teamProvider gives each team the servers named after it.
+type teamProvider struct {
+	provider
+}
+
+func (p *teamProvider) ClientsFor(req *http.Request) Clients {
+	team := req.Header.Get("X-Team")
+	return Clients{team: p.clients[team]}
+}
+
+func TestRequestProvider(t *testing.T) {
+	ctx := context.Background()
+	clients := Clients{
+		"red":  &testClient{server: createTestServer("red")},
+		"blue": &testClient{server: createTestServer("blue")},
+	}
+	server := httptest.NewServer(Handler(&teamProvider{provider{clients: clients}}))
+	t.Cleanup(server.Close)
+
+	session, err := connectHTTP(ctx, t, server.URL, http.Header{"X-Team": {"blue"}})
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	if names := toolNames(ctx, t, session); !slices.Equal(names, []string{"blue.echo"}) {
+		t.Errorf("Expected only blue.echo, got %v", names)
+	}
+}
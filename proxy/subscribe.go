@@ -0,0 +1,157 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// route finds the backend that owns a rewritten resource URI,
+// and returns the URI as the backend knows it.
+func (p *proxy) route(uri string) (*backend, string, error) {
+	rest, ok := strings.CutPrefix(uri, scheme)
+	if !ok {
+		return nil, "", mcp.ResourceNotFoundError(uri)
+	}
+	prefix, _, _ := strings.Cut(rest, "/")
+
+	p.mu.Lock()
+	b, ok := p.backends[prefix]
+	p.mu.Unlock()
+	if !ok {
+		return nil, "", mcp.ResourceNotFoundError(uri)
+	}
+
+	original, _ := restoreURI(prefix, uri)
+	return b, original, nil
+}
+
+// subscribe forwards a frontend subscription to the owning backend.
+func (p *proxy) subscribe(ctx context.Context, req *mcp.SubscribeRequest) error {
+	if err := p.authorize(req.Extra, KindResource, req.Params.URI); err != nil {
+		return err
+	}
+
+	b, uri, err := p.route(req.Params.URI)
+	if err != nil {
+		return err
+	}
+	return b.subscribe(ctx, p, uri)
+}
+
+// unsubscribe forwards a frontend unsubscription to the owning backend.
+func (p *proxy) unsubscribe(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+	b, uri, err := p.route(req.Params.URI)
+	if err != nil {
+		return err
+	}
+	return b.unsubscribe(ctx, p, uri)
+}
+
+// subscribe subscribes a proxy to a backend resource.
+// The backend itself is only subscribed once, however many proxies are.
+func (b *backend) subscribe(ctx context.Context, p *proxy, uri string) error {
+	b.subMu.Lock()
+	defer b.subMu.Unlock()
+
+	b.mu.Lock()
+	c, attached := b.proxies[p]
+	session := b.session
+	b.mu.Unlock()
+	if !attached || session == nil {
+		return fmt.Errorf("server %q is unavailable", b.name)
+	}
+	if c.subscriptions[uri] {
+		return nil
+	}
+
+	if b.subscriptions[uri] == 0 {
+		if caps := capabilities(session); caps.Resources == nil || !caps.Resources.Subscribe {
+			return fmt.Errorf("server %q does not support resource subscriptions", b.name)
+		}
+		if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: uri}); err != nil {
+			return err
+		}
+	}
+
+	c.subscriptions[uri] = true
+	b.subscriptions[uri]++
+	return nil
+}
+
+// unsubscribe releases a proxy's subscription,
+// unsubscribing the backend once nothing else is subscribed.
+func (b *backend) unsubscribe(ctx context.Context, p *proxy, uri string) error {
+	b.subMu.Lock()
+	defer b.subMu.Unlock()
+
+	b.mu.Lock()
+	c, attached := b.proxies[p]
+	session := b.session
+	b.mu.Unlock()
+	if !attached || !c.subscriptions[uri] {
+		return nil
+	}
+
+	return b.release(ctx, session, c, uri)
+}
+
+// release drops one subscription to uri. subMu must be held.
+func (b *backend) release(ctx context.Context, session *mcp.ClientSession, c *caches, uri string) error {
+	delete(c.subscriptions, uri)
+	b.subscriptions[uri]--
+	if b.subscriptions[uri] > 0 {
+		return nil
+	}
+
+	delete(b.subscriptions, uri)
+	if session == nil {
+		return nil
+	}
+	return session.Unsubscribe(ctx, &mcp.UnsubscribeParams{URI: uri})
+}
+
+// releaseAll drops every subscription held by c.
+func (b *backend) releaseAll(c *caches) {
+	b.subMu.Lock()
+	defer b.subMu.Unlock()
+
+	b.mu.Lock()
+	session := b.session
+	b.mu.Unlock()
+
+	for uri := range c.subscriptions {
+		if err := b.release(b.ctx, session, c, uri); err != nil {
+			slog.Error("failed to unsubscribe", "name", b.name, "uri", uri, "err", err)
+		}
+	}
+}
+
+// resubscribe restores the backend's subscriptions on a new session.
+func (b *backend) resubscribe(ctx context.Context, session *mcp.ClientSession) {
+	b.subMu.Lock()
+	defer b.subMu.Unlock()
+
+	for uri := range b.subscriptions {
+		if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: uri}); err != nil {
+			slog.Error("failed to resubscribe", "name", b.name, "uri", uri, "err", err)
+		}
+	}
+}
+
+// resourceUpdated relays a backend update to the proxies, whose servers
+// notify only the frontend sessions subscribed to the resource.
+func (b *backend) resourceUpdated(ctx context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+	params := &mcp.ResourceUpdatedNotificationParams{
+		URI: rewriteURI(b.prefix, req.Params.URI),
+	}
+
+	for p := range b.attached() {
+		if err := p.server.ResourceUpdated(ctx, params); err != nil {
+			slog.Error("failed to relay resource update", "name", b.name, "uri", params.URI, "err", err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package proxy
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestSubscriptions(t *testing.T) {
+	ctx := context.Background()
+
+	var subscribes, unsubscribes atomic.Int32
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.1.0"}, &mcp.ServerOptions{
+		SubscribeHandler: func(context.Context, *mcp.SubscribeRequest) error {
+			subscribes.Add(1)
+			return nil
+		},
+		UnsubscribeHandler: func(context.Context, *mcp.UnsubscribeRequest) error {
+			unsubscribes.Add(1)
+			return nil
+		},
+	})
+	server.AddResource(
+		&mcp.Resource{Name: "log", URI: "test://log"},
+		func(_ context.Context, _ *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{Text: "log"}}}, nil
+		},
+	)
+
+	clients := Clients{"backend": WithOptions(&testClient{server: server}, Options{Shared: true})}
+	m := newManager(&provider{clients: clients})
+
+	// Connect two frontends that record resource updates
+	updates := make(chan string, 2)
+	opts := &mcp.ClientOptions{
+		ResourceUpdatedHandler: func(_ context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			updates <- req.Params.URI
+		},
+	}
+	first, second := connectClient(ctx, t, m, opts), connectClient(ctx, t, m, opts)
+
+	const uri = "chimera://backend/test://log"
+	for _, session := range []*mcp.ClientSession{first, second} {
+		if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: uri}); err != nil {
+			t.Fatalf("Failed to subscribe: %v", err)
+		}
+	}
+
+	if n := subscribes.Load(); n != 1 {
+		t.Errorf("Expected 1 backend subscription, got %d", n)
+	}
+
+	if err := server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: "test://log"}); err != nil {
+		t.Fatalf("Failed to send update: %v", err)
+	}
+
+	for range 2 {
+		if got := <-updates; got != uri {
+			t.Errorf("Expected update for %q, got %q", uri, got)
+		}
+	}
+
+	// The backend is unsubscribed with the last frontend
+	if err := first.Unsubscribe(ctx, &mcp.UnsubscribeParams{URI: uri}); err != nil {
+		t.Fatalf("Failed to unsubscribe: %v", err)
+	}
+	if n := unsubscribes.Load(); n != 0 {
+		t.Errorf("Expected backend to stay subscribed, got %d unsubscribes", n)
+	}
+
+	if err := second.Unsubscribe(ctx, &mcp.UnsubscribeParams{URI: uri}); err != nil {
+		t.Fatalf("Failed to unsubscribe: %v", err)
+	}
+	if n := unsubscribes.Load(); n != 1 {
+		t.Errorf("Expected 1 backend unsubscription, got %d", n)
+	}
+}
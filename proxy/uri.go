@@ -0,0 +1,36 @@
+package proxy
+
+import (
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// scheme is the URI scheme of every proxied resource.
+const scheme = "chimera://"
+
+// rewriteURI moves a backend resource URI (or URI template) under the proxy's
+// namespace, as chimera://<prefix>/<original>. The original is kept verbatim
+// so templates still expand, and so the mapping is trivially reversible.
+func rewriteURI(prefix, uri string) string {
+	return scheme + prefix + "/" + uri
+}
+
+// restoreURI reverses rewriteURI, reporting whether uri belongs to prefix.
+func restoreURI(prefix, uri string) (string, bool) {
+	return strings.CutPrefix(uri, scheme+prefix+"/")
+}
+
+// rewriteContents rewrites the URIs of resource contents returned by a backend.
+func rewriteContents(prefix string, res *mcp.ReadResourceResult) *mcp.ReadResourceResult {
+	if res == nil {
+		return nil
+	}
+
+	for _, c := range res.Contents {
+		if c != nil && c.URI != "" {
+			c.URI = rewriteURI(prefix, c.URI)
+		}
+	}
+	return res
+}